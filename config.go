@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+)
+
+/*
+ * The config file lets a team check their templates into their repo, eg:
+ *   {
+ *     "suiteTemplate": "tools/ginkgo/suite.tmpl",
+ *     "specTemplate": "tools/ginkgo/spec.tmpl"
+ *   }
+ * Paths are relative to the config file.
+ */
+type config struct {
+	SuiteTemplate string `json:"suiteTemplate"`
+	SpecTemplate  string `json:"specTemplate"`
+}
+
+func readConfig(path string) (config, error) {
+	cfg := config{}
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("Error reading config file '%s':\n%s\n", path, err.Error())
+	}
+
+	err = json.Unmarshal(contents, &cfg)
+	if err != nil {
+		return cfg, fmt.Errorf("Error parsing config file '%s':\n%s\n", path, err.Error())
+	}
+
+	configDir := filepath.Dir(path)
+	if cfg.SuiteTemplate != "" && !filepath.IsAbs(cfg.SuiteTemplate) {
+		cfg.SuiteTemplate = filepath.Join(configDir, cfg.SuiteTemplate)
+	}
+	if cfg.SpecTemplate != "" && !filepath.IsAbs(cfg.SpecTemplate) {
+		cfg.SpecTemplate = filepath.Join(configDir, cfg.SpecTemplate)
+	}
+	return cfg, nil
+}
+
+/*
+ * Reads a template file, if one was given
+ */
+func readTemplate(path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("Error reading template '%s':\n%s\n", path, err.Error())
+	}
+	return string(contents), nil
+}
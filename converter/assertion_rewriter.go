@@ -0,0 +1,335 @@
+package converter
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+/*
+ * The *testing.T methods that fail a test, and whether they also end it
+ */
+var failureMethods = map[string]bool{
+	"Error":   false,
+	"Errorf":  false,
+	"Fatal":   true,
+	"Fatalf":  true,
+	"Fail":    false,
+	"FailNow": true,
+}
+
+/*
+ * Walks every block of statements in the specs, replacing guards like
+ *   if got != want { T().Errorf("got %v want %v", got, want) }
+ * with Expect(got).To(Equal(want), "got %v want %v", got, want).
+ * This runs once *testing.T has been replaced, so that subtests are
+ * handled the same way as tests, whatever their T was called.
+ */
+func (r *fileRewriter) rewriteAssertions(root ast.Node) {
+	collapsed := []lineSpan{}
+	ast.Inspect(root, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.BlockStmt:
+			node.List = r.rewriteAssertionsInStatements(node.List, &collapsed)
+		case *ast.CaseClause:
+			node.Body = r.rewriteAssertionsInStatements(node.Body, &collapsed)
+		case *ast.CommClause:
+			node.Body = r.rewriteAssertionsInStatements(node.Body, &collapsed)
+		}
+		return true
+	})
+
+	// once every guard is rewritten, so that the lines reported are still the file's
+	for _, span := range collapsed {
+		r.collapseLines(span.start, span.end)
+	}
+}
+
+type lineSpan struct {
+	start, end token.Pos
+}
+
+func (r *fileRewriter) rewriteAssertionsInStatements(statements []ast.Stmt, collapsed *[]lineSpan) []ast.Stmt {
+	rewritten := make([]ast.Stmt, 0, len(statements))
+	stricter, stricterMethod := token.NoPos, ""
+	for index, statement := range statements {
+		ifStmt, ok := statement.(*ast.IfStmt)
+		if !ok {
+			rewritten = append(rewritten, statement)
+			continue
+		}
+
+		expectation, method, ok := r.assertionForGuard(ifStmt)
+		if ok && !initCanBeHoisted(ifStmt, statements) {
+			expectation, ok = expectationToSucceed(ifStmt, expectation)
+			if ok {
+				ifStmt.Init = nil
+			}
+		}
+
+		if !ok {
+			rewritten = append(rewritten, statement)
+			continue
+		}
+
+		firstLine := ifStmt.Pos()
+		if ifStmt.Init != nil {
+			rewritten = append(rewritten, ifStmt.Init)
+			firstLine = ifStmt.Body.Lbrace + 1
+		}
+		rewritten = append(rewritten, expectation)
+		*collapsed = append(*collapsed, lineSpan{start: firstLine, end: ifStmt.End()})
+		r.usesGomega = true
+
+		if !failureMethods[method] && index < len(statements)-1 && !stricter.IsValid() {
+			stricter, stricterMethod = ifStmt.Pos(), method
+		}
+	}
+
+	// once per block, rather than for each of its guards
+	if stricter.IsValid() {
+		r.report(stricter, "the %s in this guard and the guards after it now end the spec when they fail, where the test would carry on", stricterMethod)
+	}
+	return rewritten
+}
+
+/*
+ * Merges the lines from start to end into one, so that an assertion that
+ * replaced several lines is printed with the same spacing as they were
+ */
+func (r *fileRewriter) collapseLines(start, end token.Pos) {
+	file := r.fileSet.File(start)
+	if file == nil {
+		return
+	}
+
+	line := file.Line(start)
+	for count := file.Line(end) - line; count > 0 && line < file.LineCount(); count-- {
+		file.MergeLine(line)
+	}
+}
+
+/*
+ * Given an if statement whose body only fails the test, returns the
+ * assertion that it should become, and the method failing the test
+ */
+func (r *fileRewriter) assertionForGuard(ifStmt *ast.IfStmt) (*ast.ExprStmt, string, bool) {
+	if ifStmt.Else != nil || len(ifStmt.Body.List) != 1 {
+		return nil, "", false
+	}
+
+	method, args, ok := r.failureCall(ifStmt.Body.List[0])
+	if !ok {
+		return nil, "", false
+	}
+
+	description := descriptionForFailure(method, args)
+	actual, negated, matcher, ok := assertionForErrorCheck(ifStmt.Cond)
+	if ok && descriptionRepeatsError(description, actual) {
+		description = nil
+	} else if !ok {
+		actual, negated, matcher, ok = assertionForCondition(ifStmt.Cond)
+	}
+
+	if !ok {
+		return nil, "", false
+	}
+
+	return createExpectation(ifStmt.Pos(), actual, negated, matcher, description), method, true
+}
+
+/*
+ * Recognises T().Errorf(...) and the other failing methods of a T,
+ * where T() is the call that replaced the *testing.T
+ */
+func (r *fileRewriter) failureCall(statement ast.Stmt) (method string, args []ast.Expr, ok bool) {
+	exprStmt, ok := statement.(*ast.ExprStmt)
+	if !ok {
+		return "", nil, false
+	}
+
+	callExpr, ok := exprStmt.X.(*ast.CallExpr)
+	if !ok {
+		return "", nil, false
+	}
+
+	selectorExpr, ok := callExpr.Fun.(*ast.SelectorExpr)
+	if !ok || !r.target.backend.isT(selectorExpr.X) {
+		return "", nil, false
+	}
+
+	if _, fails := failureMethods[selectorExpr.Sel.Name]; !fails {
+		return "", nil, false
+	}
+	return selectorExpr.Sel.Name, callExpr.Args, true
+}
+
+/*
+ * Turns the condition that made a test fail into the assertion that
+ * it holds the other way, eg: got != 3 becomes Expect(got).To(BeNumerically("==", 3)),
+ * and got != want becomes Expect(got == want).To(BeTrue())
+ */
+func assertionForCondition(cond ast.Expr) (actual ast.Expr, negated bool, matcher ast.Expr, ok bool) {
+	switch cond := cond.(type) {
+	case *ast.ParenExpr:
+		return assertionForCondition(cond.X)
+
+	case *ast.Ident:
+		if cond.Name == "true" || cond.Name == "false" || cond.Name == "nil" {
+			return nil, false, nil, false
+		}
+		return cond, false, createMatcher("BeFalse"), true
+
+	case *ast.UnaryExpr:
+		if cond.Op != token.NOT {
+			return nil, false, nil, false
+		}
+
+		if args, ok := selectorCallArgs(cond.X, "reflect", "DeepEqual"); ok && len(args) == 2 {
+			return args[0], false, createMatcher("Equal", args[1]), true
+		}
+		return cond.X, false, createMatcher("BeTrue"), true
+
+	case *ast.BinaryExpr:
+		left, right, op := cond.X, cond.Y, cond.Op
+		if (isNilIdent(left) || isConstantLiteral(left)) && !isConstantLiteral(right) {
+			left, right, op = right, left, mirrorComparison(op)
+		}
+
+		switch op {
+		case token.EQL, token.NEQ:
+			negated := op == token.EQL
+			if isNilIdent(right) {
+				return left, negated, createMatcher("BeNil"), true
+			}
+
+			if args, ok := identCallArgs(left, "len"); ok && len(args) == 1 {
+				return args[0], negated, createMatcher("HaveLen", right), true
+			}
+
+			if isNumericLiteral(right) {
+				return left, negated, createNumericMatcher("==", right), true
+			}
+
+			// matchers compare the operands' dynamic types too, which == leaves to the compiler,
+			// eg: a State against "running", or an int64 against an untyped constant
+			comparison := &ast.BinaryExpr{X: left, Op: token.EQL, Y: right}
+			if negated {
+				return comparison, false, createMatcher("BeFalse"), true
+			}
+			return comparison, false, createMatcher("BeTrue"), true
+
+		case token.LSS:
+			return left, false, createNumericMatcher(">=", right), true
+		case token.GTR:
+			return left, false, createNumericMatcher("<=", right), true
+		case token.LEQ:
+			return left, false, createNumericMatcher(">", right), true
+		case token.GEQ:
+			return left, false, createNumericMatcher("<", right), true
+		}
+	}
+	return nil, false, nil, false
+}
+
+/*
+ * The comparison with its operands swapped, eg: 0 < x is x > 0
+ */
+func mirrorComparison(op token.Token) token.Token {
+	switch op {
+	case token.LSS:
+		return token.GTR
+	case token.GTR:
+		return token.LSS
+	case token.LEQ:
+		return token.GEQ
+	case token.GEQ:
+		return token.LEQ
+	}
+	return op
+}
+
+/*
+ * An if statement's init (eg: if got := f(); got != want) is kept before
+ * the assertion, so the names it declares must not be used anywhere else
+ * in the surrounding statements
+ */
+func initCanBeHoisted(ifStmt *ast.IfStmt, statements []ast.Stmt) bool {
+	assignStmt, ok := ifStmt.Init.(*ast.AssignStmt)
+	if ifStmt.Init == nil {
+		return true
+	} else if !ok || assignStmt.Tok != token.DEFINE {
+		return false
+	}
+
+	for _, lhs := range assignStmt.Lhs {
+		ident, ok := lhs.(*ast.Ident)
+		if !ok {
+			return false
+		}
+
+		for _, statement := range statements {
+			if statement != ast.Stmt(ifStmt) && countIdentifiers(statement, ident.Name) > 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isNilIdent(expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == "nil"
+}
+
+func isConstantLiteral(expr ast.Expr) bool {
+	switch expr := expr.(type) {
+	case *ast.BasicLit:
+		return true
+	case *ast.UnaryExpr:
+		return expr.Op == token.SUB && isConstantLiteral(expr.X)
+	case *ast.Ident:
+		return expr.Name == "true" || expr.Name == "false"
+	}
+	return false
+}
+
+func isNumericLiteral(expr ast.Expr) bool {
+	switch expr := expr.(type) {
+	case *ast.BasicLit:
+		return expr.Kind == token.INT || expr.Kind == token.FLOAT || expr.Kind == token.CHAR
+	case *ast.UnaryExpr:
+		return expr.Op == token.SUB && isNumericLiteral(expr.X)
+	}
+	return false
+}
+
+/*
+ * The args of a call to a builtin or local func, eg: len(x)
+ */
+func identCallArgs(expr ast.Expr, name string) ([]ast.Expr, bool) {
+	callExpr, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return nil, false
+	}
+
+	ident, ok := callExpr.Fun.(*ast.Ident)
+	return callExpr.Args, ok && ident.Name == name
+}
+
+/*
+ * The args of a call to a package's func, eg: reflect.DeepEqual(a, b)
+ */
+func selectorCallArgs(expr ast.Expr, packageName, name string) ([]ast.Expr, bool) {
+	callExpr, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return nil, false
+	}
+
+	selectorExpr, ok := callExpr.Fun.(*ast.SelectorExpr)
+	if !ok || selectorExpr.Sel.Name != name {
+		return nil, false
+	}
+
+	ident, ok := selectorExpr.X.(*ast.Ident)
+	return callExpr.Args, ok && ident.Name == packageName
+}
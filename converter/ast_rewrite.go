@@ -0,0 +1,120 @@
+package converter
+
+import (
+	"go/ast"
+	"go/token"
+	"reflect"
+)
+
+var (
+	exprType      = reflect.TypeOf((*ast.Expr)(nil)).Elem()
+	exprSliceType = reflect.TypeOf([]ast.Expr{})
+	posType       = reflect.TypeOf(token.NoPos)
+)
+
+/*
+ * Walks every node below root, calling replace with each expression that
+ * is held in an ast.Expr field (eg: the X of a CallExpr's args) and putting
+ * whatever it returns in its place. go/ast can only inspect nodes, so this
+ * is how an expression is swapped for one of a different type,
+ * eg: tc.input for input
+ */
+func replaceExpressions(root ast.Node, replace func(ast.Expr) ast.Expr) {
+	ast.Inspect(root, func(node ast.Node) bool {
+		if node == nil {
+			return false
+		}
+
+		value := reflect.ValueOf(node)
+		if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+			return true
+		}
+
+		structValue := value.Elem()
+		for index := 0; index < structValue.NumField(); index++ {
+			field := structValue.Field(index)
+			if !field.CanSet() {
+				continue
+			}
+
+			switch field.Type() {
+			case exprType:
+				if field.IsNil() {
+					continue
+				}
+
+				expr := field.Interface().(ast.Expr)
+				if replacement := replace(expr); replacement != expr {
+					field.Set(reflect.ValueOf(&replacement).Elem())
+				}
+			case exprSliceType:
+				exprs := field.Interface().([]ast.Expr)
+				for exprIndex, expr := range exprs {
+					exprs[exprIndex] = replace(expr)
+				}
+			}
+		}
+		return true
+	})
+}
+
+/*
+ * Replaces statements in every block below root, removing those that are
+ * replaced with nil. Returns whether any statement was replaced with another.
+ */
+func replaceStatements(root ast.Node, replacements map[ast.Stmt]ast.Stmt) (replaced bool) {
+	rewrite := func(statements []ast.Stmt) []ast.Stmt {
+		rewritten := make([]ast.Stmt, 0, len(statements))
+		for _, statement := range statements {
+			if replacement, ok := replacements[statement]; ok {
+				statement = replacement
+				replaced = replaced || replacement != nil
+			}
+
+			if statement != nil {
+				rewritten = append(rewritten, statement)
+			}
+		}
+		return rewritten
+	}
+
+	ast.Inspect(root, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.BlockStmt:
+			node.List = rewrite(node.List)
+		case *ast.CaseClause:
+			node.Body = rewrite(node.Body)
+		case *ast.CommClause:
+			node.Body = rewrite(node.Body)
+		}
+		return true
+	})
+	return replaced
+}
+
+/*
+ * Zeroes every position below root, eg: in code parsed from a snippet
+ * rather than from the file it is added to, so that it is laid out as
+ * if it had been built node by node
+ */
+func clearPositions(root ast.Node) {
+	ast.Inspect(root, func(node ast.Node) bool {
+		if node == nil {
+			return false
+		}
+
+		value := reflect.ValueOf(node)
+		if value.Kind() != reflect.Ptr || value.Elem().Kind() != reflect.Struct {
+			return true
+		}
+
+		structValue := value.Elem()
+		for index := 0; index < structValue.NumField(); index++ {
+			field := structValue.Field(index)
+			if field.CanSet() && field.Type() == posType {
+				field.SetInt(0)
+			}
+		}
+		return true
+	})
+}
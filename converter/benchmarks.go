@@ -0,0 +1,367 @@
+package converter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+const gmeasureImportPath = "github.com/onsi/gomega/gmeasure"
+
+/*
+ * How many times (at most) and for how long the loop of a benchmark is sampled
+ */
+const (
+	benchmarkSamples  = "100"
+	benchmarkDuration = "Second"
+)
+
+/*
+ * A benchmark's statements, split around its b.N loop
+ */
+type benchmarkParts struct {
+	before []ast.Stmt
+	after  []ast.Stmt
+
+	// the loop's body, the name of its index (if it has one), and the
+	// b.N or b.Loop that it checks
+	loop   *ast.BlockStmt
+	index  string
+	header ast.Node
+}
+
+/*
+ * Adds an It to the describe for each benchmark that can be converted,
+ * importing gmeasure for them
+ */
+func (r *fileRewriter) convertBenchmarks(describe *ast.ExprStmt) error {
+	for _, benchmark := range findBenchmarkFuncs(r.rootNode) {
+		spec, ok := r.createSpecForBenchmark(benchmark)
+		if !ok {
+			continue
+		}
+
+		b := namedTestingTArg(benchmark)
+		replaceCleanupAndHelperCalls(benchmark.Body, b)
+		err := rewriteTestFuncAsItStatement(benchmark, spec, b, r.rootNode, describe, r.target.backend)
+		if err != nil {
+			return err
+		}
+	}
+
+	if r.usesMeasurements {
+		addImport(r.rootNode, "", gmeasureImportPath)
+		addImport(r.rootNode, "", "time")
+	}
+	return nil
+}
+
+/*
+ * Converts a benchmark into an It that samples the duration of its loop
+ * with a gmeasure Experiment, eg:
+ *   func BenchmarkParse(b *testing.B) {
+ *     input := load()
+ *     b.ResetTimer()
+ *     for i := 0; i < b.N; i++ { parse(input) }
+ *   }
+ * becomes
+ *   It("parse", Label("benchmark"), func() {
+ *     experiment := gmeasure.NewExperiment("parse")
+ *     AddReportEntry(experiment.Name, experiment)
+ *     input := load()
+ *     experiment.SampleDuration("parse", func(i int) { parse(input) },
+ *       gmeasure.SamplingConfig{N: 100, Duration: time.Second})
+ *   })
+ * Only the loop is timed, so b.ResetTimer() goes away. b.ReportMetric
+ * records a value in the experiment. Neither allocations nor throughput
+ * are measured, so b.ReportAllocs() and b.SetBytes() are removed (and
+ * reported). ok is false (reporting why) when the benchmark cannot be
+ * converted, in which case it is left as it is.
+ */
+func (r *fileRewriter) createSpecForBenchmark(benchmark *ast.FuncDecl) (ast.Stmt, bool) {
+	if !r.target.hasMeasurements {
+		r.report(benchmark.Pos(), "gmeasure experiments are reported with ginkgo v2; left %s as it is", benchmark.Name.Name)
+		return nil, false
+	}
+
+	b := namedTestingTArg(benchmark)
+	parts, reason := splitBenchmark(benchmark.Body, b)
+	if reason == "" {
+		reason = checkBenchmarkUses(benchmark.Body, b, parts)
+	}
+
+	if reason == "" && countIdentifiers(benchmark.Body, "experiment") > 0 {
+		reason = "it already has something named experiment"
+	}
+
+	if reason != "" {
+		r.report(benchmark.Pos(), "could not convert %s: %s; left it as it is", benchmark.Name.Name, reason)
+		return nil, false
+	}
+
+	name := &ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf("%q", rewriteBenchmarkName(benchmark.Name.Name))}
+	experiment := &ast.Ident{Name: "experiment"}
+	newExperiment := &ast.AssignStmt{
+		Lhs: []ast.Expr{experiment},
+		Tok: token.DEFINE,
+		Rhs: []ast.Expr{&ast.CallExpr{Fun: gmeasureSelector("NewExperiment"), Args: []ast.Expr{name}}},
+	}
+	addReportEntry := &ast.ExprStmt{X: &ast.CallExpr{
+		Fun:  &ast.Ident{Name: "AddReportEntry"},
+		Args: []ast.Expr{&ast.SelectorExpr{X: experiment, Sel: &ast.Ident{Name: "Name"}}, experiment},
+	}}
+
+	statements := []ast.Stmt{newExperiment, addReportEntry}
+	statements = append(statements, r.replaceBenchmarkCalls(parts.before, b)...)
+	statements = append(statements, sampleDuration(name, parts.loop, parts.index))
+	statements = append(statements, r.replaceBenchmarkCalls(parts.after, b)...)
+
+	it := createGinkgoStatement("It", name, statements)
+	callExpr := it.X.(*ast.CallExpr)
+	label := &ast.CallExpr{Fun: &ast.Ident{Name: "Label"}, Args: []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: `"benchmark"`}}}
+	callExpr.Args = []ast.Expr{callExpr.Args[0], label, callExpr.Args[1]}
+
+	r.usesMeasurements = true
+	return it, true
+}
+
+/*
+ * eg: "BenchmarkParseConfig" becomes "parse config"
+ */
+func rewriteBenchmarkName(benchmarkName string) string {
+	return rewriteTestName("Test" + strings.TrimPrefix(benchmarkName, "Benchmark"))
+}
+
+/*
+ * Finds the b.N loop, which must be one of the benchmark's top level
+ * statements, eg:
+ *   for i := 0; i < b.N; i++ { ... }
+ *   for i := range b.N { ... }
+ *   for b.Loop() { ... }
+ */
+func splitBenchmark(body *ast.BlockStmt, b string) (parts benchmarkParts, reason string) {
+	if b == "" {
+		return parts, "it has no b.N loop"
+	}
+
+	for index, statement := range body.List {
+		loop, loopIndex, header, ok := benchmarkLoop(statement, b)
+		if !ok {
+			continue
+		}
+
+		if parts.loop != nil {
+			return parts, "it has more than one b.N loop"
+		}
+
+		parts.before = body.List[:index]
+		parts.loop = loop
+		parts.index = loopIndex
+		parts.header = header
+		parts.after = body.List[index+1:]
+	}
+
+	if parts.loop == nil {
+		return parts, "it has no b.N loop at the top of its body"
+	}
+	return parts, ""
+}
+
+/*
+ * The body of a b.N loop, the name of its index (if it has one) and the
+ * b.N or b.Loop it checks
+ */
+func benchmarkLoop(statement ast.Stmt, b string) (loop *ast.BlockStmt, index string, header ast.Node, ok bool) {
+	switch statement := statement.(type) {
+	case *ast.ForStmt:
+		if statement.Init == nil && statement.Post == nil {
+			callExpr, ok := statement.Cond.(*ast.CallExpr)
+			if ok && len(callExpr.Args) == 0 && isSelectorOf(callExpr.Fun, b, "Loop") {
+				return statement.Body, "", callExpr.Fun, true
+			}
+			return nil, "", nil, false
+		}
+
+		init, ok := statement.Init.(*ast.AssignStmt)
+		if !ok || init.Tok != token.DEFINE || len(init.Lhs) != 1 || len(init.Rhs) != 1 {
+			return nil, "", nil, false
+		}
+
+		ident, ok := init.Lhs[0].(*ast.Ident)
+		if !ok || !isBasicLit(init.Rhs[0], token.INT, "0") {
+			return nil, "", nil, false
+		}
+
+		cond, ok := statement.Cond.(*ast.BinaryExpr)
+		if !ok || cond.Op != token.LSS || !isIdentNamed(cond.X, ident.Name) || !isSelectorOf(cond.Y, b, "N") {
+			return nil, "", nil, false
+		}
+
+		post, ok := statement.Post.(*ast.IncDecStmt)
+		if !ok || post.Tok != token.INC || !isIdentNamed(post.X, ident.Name) {
+			return nil, "", nil, false
+		}
+		return statement.Body, ident.Name, cond.Y, true
+
+	case *ast.RangeStmt:
+		if statement.Value != nil || !isSelectorOf(statement.X, b, "N") {
+			return nil, "", nil, false
+		}
+
+		if statement.Key == nil {
+			return statement.Body, "", statement.X, true
+		}
+
+		ident, ok := statement.Key.(*ast.Ident)
+		if !ok || statement.Tok != token.DEFINE {
+			return nil, "", nil, false
+		}
+		return statement.Body, ident.Name, statement.X, true
+	}
+	return nil, "", nil, false
+}
+
+/*
+ * Besides its b.N loop, a benchmark can use b for the same things as a
+ * *testing.T, and for the calls replaceBenchmarkCalls converts, as long as
+ * they are made directly in the benchmark's body. b.N, timers,
+ * sub-benchmarks and parallel benchmarks have no gmeasure equivalent.
+ */
+func checkBenchmarkUses(body *ast.BlockStmt, b string, parts benchmarkParts) (reason string) {
+	converted := map[ast.Node]bool{parts.header: true}
+	markConverted := func(statements []ast.Stmt, names ...string) {
+		for _, statement := range statements {
+			exprStmt, ok := statement.(*ast.ExprStmt)
+			if !ok {
+				continue
+			}
+
+			for _, name := range names {
+				if callExpr, ok := exprStmt.X.(*ast.CallExpr); ok && isSelectorOf(callExpr.Fun, b, name) {
+					converted[callExpr.Fun] = true
+				}
+			}
+		}
+	}
+	markConverted(parts.before, "ResetTimer", "ReportAllocs", "SetBytes", "ReportMetric")
+	markConverted(parts.after, "ReportAllocs", "SetBytes", "ReportMetric")
+
+	selected := 0
+	ast.Inspect(body, func(node ast.Node) bool {
+		if !isSelectorOf(node, b, "") {
+			return reason == ""
+		}
+
+		selected++
+		name := node.(*ast.SelectorExpr).Sel.Name
+		switch name {
+		case "N", "Loop", "StartTimer", "StopTimer", "Run", "RunParallel", "Elapsed":
+			if !converted[node] {
+				reason = fmt.Sprintf("%s.%s has no gmeasure equivalent", b, name)
+			}
+		case "ReportAllocs", "SetBytes", "ReportMetric", "ResetTimer":
+			if !converted[node] {
+				reason = fmt.Sprintf("%s.%s is only converted when called directly in the benchmark's body (before its loop, for ResetTimer)", b, name)
+			}
+		}
+		return reason == ""
+	})
+
+	if reason == "" && countUnqualifiedIdentifiers(body, b) != selected {
+		reason = fmt.Sprintf("it passes %s on to other funcs", b)
+	}
+	return reason
+}
+
+/*
+ * Removes b.ResetTimer(), b.ReportAllocs() and b.SetBytes(), and records
+ * the metrics reported with b.ReportMetric in the experiment, eg:
+ * b.ReportMetric(hits, "hits") becomes experiment.RecordValue("hits", hits)
+ */
+func (r *fileRewriter) replaceBenchmarkCalls(statements []ast.Stmt, b string) []ast.Stmt {
+	replaced := []ast.Stmt{}
+	for _, statement := range statements {
+		exprStmt, ok := statement.(*ast.ExprStmt)
+		if !ok {
+			replaced = append(replaced, statement)
+			continue
+		}
+
+		switch {
+		case isBenchmarkCall(exprStmt.X, b, "ResetTimer"):
+			// only the loop is timed
+			continue
+		case isBenchmarkCall(exprStmt.X, b, "ReportAllocs"):
+			r.report(statement.Pos(), "gmeasure does not measure allocations; removed %s.ReportAllocs()", b)
+			continue
+		case isBenchmarkCall(exprStmt.X, b, "SetBytes"):
+			r.report(statement.Pos(), "gmeasure does not measure throughput; removed %s.SetBytes()", b)
+			continue
+		}
+
+		args, ok := selectorCallArgs(exprStmt.X, b, "ReportMetric")
+		if ok && len(args) == 2 {
+			recordValue := &ast.SelectorExpr{X: &ast.Ident{Name: "experiment"}, Sel: &ast.Ident{Name: "RecordValue"}}
+			exprStmt.X = &ast.CallExpr{Fun: recordValue, Args: []ast.Expr{args[1], args[0]}}
+		}
+		replaced = append(replaced, exprStmt)
+	}
+	return replaced
+}
+
+/*
+ * eg: experiment.SampleDuration("parse", func(i int) { ... }, gmeasure.SamplingConfig{N: 100, Duration: time.Second})
+ */
+func sampleDuration(name *ast.BasicLit, loop *ast.BlockStmt, index string) ast.Stmt {
+	if index == "" || countIdentifiers(loop, index) == 0 {
+		index = "_"
+	}
+
+	param := &ast.Field{Names: []*ast.Ident{{Name: index}}, Type: &ast.Ident{Name: "int"}}
+	sample := &ast.FuncLit{Type: &ast.FuncType{Params: &ast.FieldList{List: []*ast.Field{param}}}, Body: loop}
+
+	config := &ast.CompositeLit{
+		Type: gmeasureSelector("SamplingConfig"),
+		Elts: []ast.Expr{
+			&ast.KeyValueExpr{Key: &ast.Ident{Name: "N"}, Value: &ast.BasicLit{Kind: token.INT, Value: benchmarkSamples}},
+			&ast.KeyValueExpr{Key: &ast.Ident{Name: "Duration"}, Value: &ast.SelectorExpr{X: &ast.Ident{Name: "time"}, Sel: &ast.Ident{Name: benchmarkDuration}}},
+		},
+	}
+
+	fun := &ast.SelectorExpr{X: &ast.Ident{Name: "experiment"}, Sel: &ast.Ident{Name: "SampleDuration"}}
+	return &ast.ExprStmt{X: &ast.CallExpr{Fun: fun, Args: []ast.Expr{name, sample, config}}}
+}
+
+func gmeasureSelector(name string) *ast.SelectorExpr {
+	return &ast.SelectorExpr{X: &ast.Ident{Name: "gmeasure"}, Sel: &ast.Ident{Name: name}}
+}
+
+/*
+ * eg: b.ResetTimer()
+ */
+func isBenchmarkCall(expr ast.Expr, b, name string) bool {
+	_, ok := selectorCallArgs(expr, b, name)
+	return ok
+}
+
+/*
+ * eg: b.N, or any selector on b when name is empty
+ */
+func isSelectorOf(node ast.Node, x, name string) bool {
+	selectorExpr, ok := node.(*ast.SelectorExpr)
+	if !ok || (name != "" && selectorExpr.Sel.Name != name) {
+		return false
+	}
+	return isIdentNamed(selectorExpr.X, x)
+}
+
+func isIdentNamed(expr ast.Expr, name string) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == name
+}
+
+func isBasicLit(expr ast.Expr, kind token.Token, value string) bool {
+	basicLit, ok := expr.(*ast.BasicLit)
+	return ok && basicLit.Kind == kind && basicLit.Value == value
+}
@@ -0,0 +1,270 @@
+package converter
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"sort"
+)
+
+/*
+ * The comments of a file as it was parsed, and the nodes they annotate
+ */
+type fileComments struct {
+	fileSet  *token.FileSet
+	rootNode *ast.File
+
+	// the nodes each comment group annotates
+	annotated map[*ast.CommentGroup][]ast.Node
+
+	// the file's declarations before rewriting, with every node inside them
+	decls []originalDecl
+}
+
+type originalDecl struct {
+	pos, end token.Pos
+	nodes    []ast.Node
+}
+
+/*
+ * Records which nodes the file's comments annotate, before the file is
+ * rewritten and those nodes move around
+ */
+func recordComments(fileSet *token.FileSet, rootNode *ast.File) fileComments {
+	comments := fileComments{
+		fileSet:   fileSet,
+		rootNode:  rootNode,
+		annotated: map[*ast.CommentGroup][]ast.Node{},
+	}
+
+	for node, groups := range ast.NewCommentMap(fileSet, rootNode, rootNode.Comments) {
+		for _, group := range groups {
+			comments.annotated[group] = append(comments.annotated[group], node)
+		}
+	}
+
+	for _, decl := range rootNode.Decls {
+		original := originalDecl{pos: decl.Pos(), end: decl.End()}
+		if funcDecl, ok := decl.(*ast.FuncDecl); ok && funcDecl.Doc != nil {
+			original.pos = funcDecl.Doc.Pos()
+		} else if genDecl, ok := decl.(*ast.GenDecl); ok && genDecl.Doc != nil {
+			original.pos = genDecl.Doc.Pos()
+		}
+
+		ast.Inspect(decl, func(node ast.Node) bool {
+			if node != nil {
+				original.nodes = append(original.nodes, node)
+			}
+			return true
+		})
+		comments.decls = append(comments.decls, original)
+	}
+	return comments
+}
+
+/*
+ * Prints the rewritten file with its comments next to the code they
+ * annotate. go/printer places comments by position, which only works while
+ * a file's declarations are in the same order as their positions, and
+ * they are not once tests have moved into a container at the end of the
+ * file. So each declaration is printed on its own, with the comments
+ * annotating the nodes it holds now. A comment whose node went away (eg:
+ * an if statement that became an assertion) goes with the closest node
+ * that was declared next to it.
+ */
+func (comments fileComments) print() ([]byte, error) {
+	rootNode := comments.rootNode
+
+	// the package clause and imports are printed first, then one chunk per declaration
+	header := []ast.Decl{}
+	chunks := [][]ast.Decl{}
+	for _, decl := range rootNode.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if ok && genDecl.Tok == token.IMPORT && len(chunks) == 0 {
+			header = append(header, decl)
+		} else {
+			chunks = append(chunks, []ast.Decl{decl})
+		}
+	}
+	chunks = append([][]ast.Decl{header}, chunks...)
+
+	// comments after the last declaration are printed on their own, once everything else is
+	chunks = append(chunks, []ast.Decl{})
+
+	owners := map[ast.Node]int{}
+	for index, chunk := range chunks {
+		for _, decl := range chunk {
+			ast.Inspect(decl, func(node ast.Node) bool {
+				if _, owned := owners[node]; node != nil && !owned {
+					owners[node] = index
+				}
+				return true
+			})
+		}
+	}
+
+	groups := make([][]*ast.CommentGroup, len(chunks))
+	for _, group := range rootNode.Comments {
+		index, ok := comments.chunkFor(group, owners, len(chunks))
+		if !ok {
+			continue
+		}
+
+		if !comments.annotatesAny(group, owners) {
+			group = moveOutOfStatement(group, chunks[index])
+		}
+		groups[index] = append(groups[index], group)
+	}
+
+	var buffer bytes.Buffer
+	for index, chunk := range chunks {
+		sort.Slice(groups[index], func(i, j int) bool {
+			return groups[index][i].Pos() < groups[index][j].Pos()
+		})
+
+		file := &ast.File{Package: rootNode.Package, Name: rootNode.Name, Decls: chunk, Comments: groups[index], Doc: rootNode.Doc}
+		if index > 0 {
+			// without positions, the printer estimates the position of generated
+			// code (eg: a new container) from the package clause instead of
+			// placing comments inside of it
+			file = &ast.File{Name: &ast.Ident{Name: rootNode.Name.Name}, Decls: chunk, Comments: groups[index]}
+		}
+
+		var printed bytes.Buffer
+		if err := format.Node(&printed, comments.fileSet, file); err != nil {
+			return nil, err
+		}
+
+		if index > 0 {
+			// every chunk but the header is printed as a file of its own
+			packageClause := fmt.Sprintf("package %s\n", rootNode.Name.Name)
+			printed.Next(len(packageClause))
+		}
+		buffer.Write(printed.Bytes())
+		buffer.WriteString("\n")
+	}
+	return format.Source(buffer.Bytes())
+}
+
+/*
+ * The index of the chunk printing a comment group: the chunk holding a node
+ * it annotates, or else the chunk holding a node from the declaration it
+ * was in. Comments outside of every declaration go with the package clause
+ * when they come before the first one, and in the last chunk otherwise,
+ * even when they annotate the last declaration. Comments on an import
+ * that was removed go with it.
+ */
+func (comments fileComments) chunkFor(group *ast.CommentGroup, owners map[ast.Node]int, count int) (int, bool) {
+	if len(comments.decls) > 0 && comments.startsAfterLastLine(group) {
+		return count - 1, true
+	}
+
+	for _, node := range comments.annotated[group] {
+		if index, ok := owners[node]; ok {
+			return index, true
+		}
+	}
+
+	for _, node := range comments.annotated[group] {
+		if _, ok := node.(*ast.ImportSpec); ok {
+			return 0, false
+		}
+	}
+
+	for _, decl := range comments.decls {
+		if group.Pos() < decl.pos || group.Pos() >= decl.end {
+			continue
+		}
+
+		for _, node := range decl.nodes {
+			if index, ok := owners[node]; ok {
+				return index, true
+			}
+		}
+		return 0, false // the whole declaration went away
+	}
+
+	if len(comments.decls) == 0 || group.Pos() < comments.decls[0].pos {
+		return 0, true
+	}
+	return count - 1, true
+}
+
+/*
+ * Whether a comment group starts on a line after the last declaration ends
+ */
+func (comments fileComments) startsAfterLastLine(group *ast.CommentGroup) bool {
+	end := comments.decls[len(comments.decls)-1].end
+	return comments.fileSet.Position(group.Pos()).Line > comments.fileSet.Position(end).Line
+}
+
+func (comments fileComments) annotatesAny(group *ast.CommentGroup, owners map[ast.Node]int) bool {
+	for _, node := range comments.annotated[group] {
+		if _, ok := owners[node]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ * A comment whose node went away would print wherever its position falls
+ * among the nodes left, which can be the middle of the statement that
+ * replaced its node, eg: between the arguments of the assertion an if
+ * statement became. Such a comment moves above that statement instead.
+ */
+func moveOutOfStatement(group *ast.CommentGroup, decls []ast.Decl) *ast.CommentGroup {
+	var statement ast.Stmt
+	for _, decl := range decls {
+		ast.Inspect(decl, func(node ast.Node) bool {
+			stmt, ok := node.(ast.Stmt)
+			if !ok {
+				return true
+			}
+
+			if stmt.Pos().IsValid() && stmt.Pos() < group.Pos() && group.Pos() < lastPosition(stmt) {
+				statement = stmt
+			}
+			return true
+		})
+	}
+
+	// comments between the statements of a block print where they are
+	if statement == nil || holdsStatements(statement) {
+		return group
+	}
+
+	moved := &ast.CommentGroup{}
+	for _, comment := range group.List {
+		// comments on the same position still print on lines of their own
+		moved.List = append(moved.List, &ast.Comment{Slash: statement.Pos() - 1, Text: comment.Text})
+	}
+	return moved
+}
+
+/*
+ * The position of the last node inside root that has one
+ */
+func lastPosition(root ast.Node) token.Pos {
+	last := root.Pos()
+	ast.Inspect(root, func(node ast.Node) bool {
+		if node != nil && node.Pos() > last {
+			last = node.Pos()
+		}
+		return true
+	})
+	return last
+}
+
+func holdsStatements(statement ast.Stmt) bool {
+	holds := false
+	ast.Inspect(statement, func(node ast.Node) bool {
+		if _, ok := node.(ast.Stmt); ok && node != statement {
+			holds = true
+		}
+		return !holds
+	})
+	return holds
+}
@@ -0,0 +1,244 @@
+/*
+ * Package converter rewrites xunit style go tests (func TestXxx(t *testing.T))
+ * as ginkgo specs. It is the engine behind the ginkgo-converter command, and
+ * can be embedded in other tools. Nothing in this package writes to disk
+ * unless asked to, and failures are reported as errors rather than panics.
+ */
+package converter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+/*
+ * Options control how a Converter rewrites tests.
+ */
+type Options struct {
+	// SkipSuiteFiles disables creating a <pkg>_suite_test.go file for
+	// packages that do not have one yet.
+	SkipSuiteFiles bool
+
+	// NonRecursive only converts the packages that were named, instead of
+	// also converting every package below them. Wildcard patterns such as
+	// ./... still match every package below their root.
+	NonRecursive bool
+
+	// SuiteTemplate is a text/template used to create new suite files,
+	// rendered with a TemplateData. Defaults to what `ginkgo bootstrap` creates.
+	SuiteTemplate string
+
+	// SpecTemplate is a text/template for the container that each converted
+	// file's Its are added to, rendered with a TemplateData. It must be a call
+	// with a func literal argument. Defaults to Describe("Testing with ginkgo", func() {})
+	SpecTemplate string
+
+	// GinkgoVersion is the version of ginkgo to convert to: "v1" (the default)
+	// or "v2". v2 uses var _ = Describe(...) containers, GinkgoT(),
+	// DeferCleanup and GinkgoHelper, and imports github.com/onsi/ginkgo/v2
+	GinkgoVersion string
+
+	// TestingT is what *testing.T is replaced with, eg: &GinkgoTBackend.
+	// Defaults to MrTBackend for ginkgo v1 and GinkgoTBackend for v2.
+	TestingT *TestingTBackend
+
+	// Testify converts calls to testify's assert and require packages into
+	// gomega assertions. Calls without a gomega equivalent are reported.
+	Testify bool
+
+	// GoConvey converts tests made of GoConvey's Convey blocks into ginkgo
+	// containers and specs, and its So assertions into gomega assertions.
+	GoConvey bool
+
+	// Benchmarks converts benchmarks into specs labelled "benchmark" that
+	// sample the duration of their b.N loop with a gmeasure Experiment.
+	// Needs ginkgo v2.
+	Benchmarks bool
+
+	// Examples converts examples with an output comment into specs that
+	// check what they print to stdout.
+	Examples bool
+}
+
+/*
+ * A Converter rewrites go test files as ginkgo specs.
+ * The zero value is ready to use with the default options.
+ */
+type Converter struct {
+	options Options
+}
+
+/*
+ * A Diagnostic is something the converter noticed but could not (or chose
+ * not to) handle automatically, eg: a test that still needs converting.
+ */
+type Diagnostic struct {
+	Pos     token.Position
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	if !d.Pos.IsValid() {
+		return d.Message
+	}
+	return fmt.Sprintf("%s:%d: %s", d.Pos.Filename, d.Pos.Line, d.Message)
+}
+
+/*
+ * A FileChange describes the contents a single file should have once the
+ * conversion is complete. Original is nil when the file does not exist yet
+ * (eg: a new suite file).
+ */
+type FileChange struct {
+	Path      string
+	Original  []byte
+	Converted []byte
+	Mode      os.FileMode
+}
+
+/*
+ * Writes the converted contents of the file to disk
+ */
+func (change FileChange) Apply() error {
+	err := ioutil.WriteFile(change.Path, change.Converted, change.Mode)
+	if err != nil {
+		return fmt.Errorf("Error writing file '%s':\n%s\n", change.Path, err.Error())
+	}
+	return nil
+}
+
+func New(options Options) *Converter {
+	return &Converter{options: options}
+}
+
+/*
+ * ConvertSource converts the tests in src with the default options.
+ * filename is only used for positions in errors and diagnostics.
+ */
+func ConvertSource(filename string, src []byte) ([]byte, []Diagnostic, error) {
+	return New(Options{}).ConvertSource(filename, src)
+}
+
+/*
+ * ConvertFile converts the tests in an already parsed file with the default options.
+ */
+func ConvertFile(fileSet *token.FileSet, rootNode *ast.File) ([]Diagnostic, error) {
+	return New(Options{}).ConvertFile(fileSet, rootNode)
+}
+
+/*
+ * ConvertSource parses src, rewrites its tests and returns the formatted result.
+ * src is returned as it is when it has no tests left to convert.
+ */
+func (c *Converter) ConvertSource(filename string, src []byte) ([]byte, []Diagnostic, error) {
+	return c.convertSource(filename, src, TemplateData{})
+}
+
+/*
+ * ConvertFile rewrites the tests in rootNode in place, leaving it untouched
+ * when it has none left to convert. Any unexpected failure while walking
+ * the AST is returned as an error.
+ * The nodes that rootNode.Comments annotate are moved without them, so use
+ * ConvertSource to keep the comments next to their code.
+ */
+func (c *Converter) ConvertFile(fileSet *token.FileSet, rootNode *ast.File) (diagnostics []Diagnostic, err error) {
+	_, diagnostics, err = c.convertFile(fileSet, rootNode, TemplateData{})
+	return diagnostics, err
+}
+
+/*
+ * data holds whatever is known about the file's package (when converting
+ * a whole package), and is completed with the details of this file.
+ */
+func (c *Converter) convertSource(filename string, src []byte, data TemplateData) ([]byte, []Diagnostic, error) {
+	fileSet := token.NewFileSet()
+	rootNode, err := parser.ParseFile(fileSet, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error parsing test file '%s':\n%s\n", filename, err.Error())
+	}
+
+	data.BuildTags = buildTagsInSource(src)
+	comments := recordComments(fileSet, rootNode)
+	rewritten, diagnostics, err := c.convertFile(fileSet, rootNode, data)
+	if err != nil {
+		return nil, diagnostics, err
+	}
+
+	if !rewritten {
+		return src, diagnostics, nil
+	}
+
+	converted, err := comments.print()
+	if err != nil {
+		return nil, diagnostics, fmt.Errorf("Error formatting ast node after rewriting tests.\n%s\n", err.Error())
+	}
+
+	return converted, diagnostics, nil
+}
+
+/*
+ * converted is false when the file had no tests left to convert, in which
+ * case it was not changed at all.
+ */
+func (c *Converter) convertFile(fileSet *token.FileSet, rootNode *ast.File, data TemplateData) (converted bool, diagnostics []Diagnostic, err error) {
+	data.PackageName = rootNode.Name.Name
+	if data.FormattedName == "" {
+		data.FormattedName = prettifyPackageName(strings.TrimSuffix(data.PackageName, "_test"))
+		data.SuiteTitle = data.FormattedName + " Suite"
+	}
+
+	target, err := c.target()
+	if err != nil {
+		return false, nil, err
+	}
+
+	rewriter := &fileRewriter{converter: c, target: target, fileSet: fileSet, rootNode: rootNode, data: data}
+	rewriter.leadingComments = findLeadingComments(fileSet, rootNode)
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("unexpected error rewriting '%s': %v", fileSet.Position(rootNode.Pos()).Filename, recovered)
+		}
+		diagnostics = rewriter.diagnostics
+	}()
+
+	converted, err = rewriter.rewriteTests()
+	return
+}
+
+/*
+ * A fileRewriter holds the state for converting a single file
+ */
+type fileRewriter struct {
+	converter   *Converter
+	target      ginkgoTarget
+	fileSet     *token.FileSet
+	rootNode    *ast.File
+	data        TemplateData
+	diagnostics []Diagnostic
+
+	// where the comments leading the file's nodes started, to lay out the code around them
+	leadingComments []leadingComment
+
+	// set once a DescribeTable has been created, to import it for ginkgo v1
+	usesTables bool
+
+	// set once an assertion has been created, to import gomega
+	usesGomega bool
+
+	// set once a benchmark has been converted, to import gmeasure and time
+	usesMeasurements bool
+}
+
+/*
+ * Records something the rewriter could not convert on its own
+ */
+func (r *fileRewriter) report(pos token.Pos, format string, args ...interface{}) {
+	diagnostic := Diagnostic{Pos: r.fileSet.Position(pos), Message: fmt.Sprintf(format, args...)}
+	r.diagnostics = append(r.diagnostics, diagnostic)
+}
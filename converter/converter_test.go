@@ -0,0 +1,261 @@
+package converter_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"go/parser"
+	"go/token"
+
+	"github.com/tjarratt/ginkgo-converter/converter"
+)
+
+func init() {
+	Describe("using the converter as a library", func() {
+		It("converts source without touching the filesystem", func() {
+			src := []byte(`package foo
+
+import (
+	"testing"
+)
+
+func TestSomethingNeat(t *testing.T) {
+	t.Fail()
+}
+`)
+
+			converted, diagnostics, err := converter.ConvertSource("foo_test.go", src)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(diagnostics).To(BeEmpty())
+			Expect(string(converted)).To(ContainSubstring(`It("something neat", func() {`))
+			Expect(string(converted)).To(ContainSubstring(`mr.T().Fail()`))
+		})
+
+		It("leaves source with no tests left to convert as it is", func() {
+			src := []byte(`package foo
+
+import "testing"
+
+func helper(t  *testing.T) {
+	t.Helper()
+}
+`)
+
+			converted, diagnostics, err := converter.ConvertSource("foo_test.go", src)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(diagnostics).To(BeEmpty())
+			Expect(converted).To(Equal(src))
+		})
+
+		It("converts an already parsed file in place", func() {
+			fileSet := token.NewFileSet()
+			rootNode, err := parser.ParseFile(fileSet, "foo_test.go", `package foo
+
+import "testing"
+
+func TestSomethingNeat(t *testing.T) {}
+`, 0)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = converter.ConvertFile(fileSet, rootNode)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rootNode.Decls).To(HaveLen(2))
+		})
+
+		It("converts to ginkgo v2 idioms", func() {
+			src := []byte(`package foo
+
+import (
+	"testing"
+)
+
+func TestSomethingNeat(t *testing.T) {
+	t.Cleanup(func() {})
+	checkSomething(t)
+}
+
+func checkSomething(t *testing.T) {
+	t.Helper()
+	t.Fail()
+}
+`)
+
+			c := converter.New(converter.Options{GinkgoVersion: "v2"})
+			converted, _, err := c.ConvertSource("foo_test.go", src)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(converted)).To(ContainSubstring(`. "github.com/onsi/ginkgo/v2"`))
+			Expect(string(converted)).NotTo(ContainSubstring(`mr_t`))
+			Expect(string(converted)).NotTo(ContainSubstring(`func init()`))
+			Expect(string(converted)).To(ContainSubstring(`var _ = Describe("Testing with ginkgo", func() {`))
+			Expect(string(converted)).To(ContainSubstring(`DeferCleanup(func() {})`))
+			Expect(string(converted)).To(ContainSubstring(`checkSomething(GinkgoT())`))
+			Expect(string(converted)).To(ContainSubstring(`func checkSomething(t GinkgoTInterface) {`))
+			Expect(string(converted)).To(ContainSubstring(`GinkgoHelper()`))
+		})
+
+		It("prints the doc comment of a test made of subtests above its container", func() {
+			src := []byte(`package foo
+
+import "testing"
+
+// TestFirst doc
+func TestFirst(t *testing.T) {
+	x := 1
+	t.Run("one", func(t *testing.T) {
+		_ = x
+	})
+}
+`)
+
+			converted, _, err := converter.ConvertSource("foo_test.go", src)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(converted)).To(ContainSubstring("Describe(\"Testing with ginkgo\", func() {\n\t\t// TestFirst doc\n\t\tDescribe(\"first\", func() {\n"))
+		})
+
+		It("reports the assert calls that now end the spec when they fail", func() {
+			src := []byte(`package foo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSomethingNeat(t *testing.T) {
+	require.NoError(t, nil)
+	assert.Equal(t, 1, 1)
+	assert.True(t, true)
+	assert.False(t, false)
+}
+`)
+
+			c := converter.New(converter.Options{Testify: true})
+			converted, diagnostics, err := c.ConvertSource("foo_test.go", src)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(converted)).NotTo(ContainSubstring(`assert.`))
+			Expect(diagnostics).To(HaveLen(1))
+			Expect(diagnostics[0].Pos.Line).To(Equal(12))
+			Expect(diagnostics[0].Message).To(ContainSubstring("assert.Equal and the assert calls after it now end the spec"))
+		})
+
+		It("reports the guards that now end the spec when they fail", func() {
+			src := []byte(`package foo
+
+import (
+	"testing"
+)
+
+func TestSomethingNeat(t *testing.T) {
+	if Get() != 1 {
+		t.Fatal("not one")
+	}
+	if Get() != 2 {
+		t.Errorf("not two")
+	}
+	if Get() != 3 {
+		t.Fail()
+	}
+}
+`)
+
+			c := converter.New(converter.Options{})
+			converted, diagnostics, err := c.ConvertSource("foo_test.go", src)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(converted)).NotTo(ContainSubstring(`Errorf`))
+			Expect(diagnostics).To(HaveLen(1))
+			Expect(diagnostics[0].Pos.Line).To(Equal(11))
+			Expect(diagnostics[0].Message).To(ContainSubstring("the Errorf in this guard and the guards after it now end the spec"))
+		})
+
+		It("reports the c.Check calls that now end the spec when they fail", func() {
+			src := []byte(`package foo
+
+import (
+	"testing"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type NeatSuite struct{}
+
+var _ = Suite(&NeatSuite{})
+
+func (s *NeatSuite) TestSomethingNeat(c *C) {
+	c.Check(Get(), Equals, 1)
+	c.Check(Get(), Equals, 2)
+	c.Check(Get(), Equals, 3)
+}
+`)
+
+			c := converter.New(converter.Options{})
+			converted, diagnostics, err := c.ConvertSource("foo_test.go", src)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(converted)).NotTo(ContainSubstring(`c.Check`))
+			Expect(diagnostics).To(HaveLen(1))
+			Expect(diagnostics[0].Pos.Line).To(Equal(16))
+			Expect(diagnostics[0].Message).To(ContainSubstring("c.Check and the checks after it now end the spec"))
+		})
+
+		It("rejects unknown ginkgo versions", func() {
+			c := converter.New(converter.Options{GinkgoVersion: "v3"})
+			_, _, err := c.ConvertSource("foo_test.go", []byte("package foo\n\nimport \"testing\"\n\nfunc TestNothing(t *testing.T) {}\n"))
+			Expect(err).To(MatchError(ContainSubstring("unknown ginkgo version 'v3'")))
+		})
+
+		It("replaces *testing.T with a custom backend", func() {
+			src := []byte(`package foo
+
+import (
+	"testing"
+)
+
+func TestSomethingNeat(t *testing.T) {
+	checkSomething(t)
+}
+
+func checkSomething(t *testing.T) {}
+`)
+
+			c := converter.New(converter.Options{TestingT: &converter.TestingTBackend{
+				PackageName: "testy",
+				ImportPath:  "example.com/testy",
+				TFunc:       "Current",
+				TType:       "T",
+			}})
+			converted, _, err := c.ConvertSource("foo_test.go", src)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(converted)).To(ContainSubstring(`testy "example.com/testy"`))
+			Expect(string(converted)).NotTo(ContainSubstring(`mr_t`))
+			Expect(string(converted)).To(ContainSubstring(`checkSomething(testy.Current())`))
+			Expect(string(converted)).To(ContainSubstring(`func checkSomething(t testy.T) {}`))
+		})
+
+		It("imports what the converted tests use, whatever the file imported before", func() {
+			converted, _, err := converter.ConvertSource("foo_test.go", []byte("package foo\n\nfunc TestNothing(t *testing.T) {\n\tt.Fail()\n}\n"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(converted)).To(ContainSubstring("import (\n\t. \"github.com/onsi/ginkgo\"\n\tmr \"github.com/tjarratt/mr_t\"\n)\n"))
+
+			converted, _, err = converter.ConvertSource("foo_test.go", []byte(`package foo
+
+import "os"
+import "testing"
+
+func TestSomethingNeat(t *testing.T) {
+	os.Getenv("HOME")
+}
+`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(converted)).To(ContainSubstring("import (\n\t. \"github.com/onsi/ginkgo\"\n\t\"os\"\n)\n"))
+			Expect(string(converted)).NotTo(ContainSubstring(`"testing"`))
+			Expect(string(converted)).NotTo(ContainSubstring(`mr_t`))
+		})
+
+		It("returns an error instead of panicking when the source cannot be converted", func() {
+			c := converter.New(converter.Options{SpecTemplate: `Describe("without a func")`})
+			_, _, err := c.ConvertSource("foo_test.go", []byte("package foo\n\nimport \"testing\"\n\nfunc TestNothing(t *testing.T) {}\n"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+}
@@ -0,0 +1,155 @@
+package converter
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+/*
+ * Error checks are the most common guards of all, eg:
+ *   if err != nil { t.Fatal(err) }
+ *   if err == nil { t.Fatal("expected an error") }
+ *   if err.Error() != "not found" { ... }
+ *   if !errors.Is(err, ErrNotFound) { ... }
+ * and become Expect(err).NotTo(HaveOccurred()), Expect(err).To(HaveOccurred()),
+ * Expect(err).To(MatchError("not found")) and Expect(err).To(MatchError(ErrNotFound)).
+ * They are recognised before any other guard, so that err != nil is not
+ * treated like any other nil check.
+ */
+func assertionForErrorCheck(cond ast.Expr) (actual ast.Expr, negated bool, matcher ast.Expr, ok bool) {
+	switch cond := cond.(type) {
+	case *ast.ParenExpr:
+		return assertionForErrorCheck(cond.X)
+
+	case *ast.UnaryExpr:
+		if cond.Op != token.NOT {
+			return nil, false, nil, false
+		}
+
+		if args, ok := selectorCallArgs(cond.X, "errors", "Is"); ok && len(args) == 2 {
+			return args[0], false, createMatcher("MatchError", args[1]), true
+		}
+
+	case *ast.CallExpr:
+		if args, ok := selectorCallArgs(cond, "errors", "Is"); ok && len(args) == 2 {
+			return args[0], true, createMatcher("MatchError", args[1]), true
+		}
+
+	case *ast.BinaryExpr:
+		if cond.Op != token.EQL && cond.Op != token.NEQ {
+			return nil, false, nil, false
+		}
+
+		left, right := cond.X, cond.Y
+		if isNilIdent(left) || isConstantLiteral(left) {
+			left, right = right, left
+		}
+
+		if isNilIdent(right) && isErrorName(left) {
+			return left, cond.Op == token.NEQ, createMatcher("HaveOccurred"), true
+		}
+
+		if err, ok := errorMessageOf(left); ok {
+			return err, cond.Op == token.EQL, createMatcher("MatchError", right), true
+		}
+	}
+	return nil, false, nil, false
+}
+
+/*
+ * Without type checking, errors are recognised by name: err, or anything
+ * ending in err or Err, eg: parseErr
+ */
+func isErrorName(expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && (strings.HasSuffix(ident.Name, "err") || strings.HasSuffix(ident.Name, "Err"))
+}
+
+/*
+ * eg: err.Error()
+ */
+func errorMessageOf(expr ast.Expr) (ast.Expr, bool) {
+	callExpr, ok := expr.(*ast.CallExpr)
+	if !ok || len(callExpr.Args) != 0 {
+		return nil, false
+	}
+
+	selectorExpr, ok := callExpr.Fun.(*ast.SelectorExpr)
+	if !ok || selectorExpr.Sel.Name != "Error" || !isErrorName(selectorExpr.X) {
+		return nil, false
+	}
+	return selectorExpr.X, true
+}
+
+/*
+ * t.Fatal(err) says nothing that HaveOccurred() does not already print,
+ * given its description of "%v", err
+ */
+func descriptionRepeatsError(description []ast.Expr, err ast.Expr) bool {
+	if len(description) != 2 {
+		return false
+	}
+
+	format, ok := description[0].(*ast.BasicLit)
+	if !ok || format.Value != `"%v"` {
+		return false
+	}
+
+	errIdent, ok := err.(*ast.Ident)
+	if !ok {
+		return false
+	}
+
+	ident, ok := description[1].(*ast.Ident)
+	return ok && ident.Name == errIdent.Name
+}
+
+/*
+ * When the error being checked is declared by the guard and cannot be
+ * moved out of it, eg: if err := save(); err != nil { t.Fatal(err) }
+ * the call itself is asserted on: Expect(save()).To(Succeed())
+ */
+func expectationToSucceed(ifStmt *ast.IfStmt, expectation *ast.ExprStmt) (*ast.ExprStmt, bool) {
+	assignStmt, ok := ifStmt.Init.(*ast.AssignStmt)
+	if !ok || len(assignStmt.Lhs) != 1 || len(assignStmt.Rhs) != 1 {
+		return nil, false
+	}
+
+	err, ok := assignStmt.Lhs[0].(*ast.Ident)
+	if !ok {
+		return nil, false
+	}
+
+	to := expectation.X.(*ast.CallExpr)
+	expect := to.Fun.(*ast.SelectorExpr).X.(*ast.CallExpr)
+	matcher, ok := to.Args[0].(*ast.CallExpr)
+	if !ok || to.Fun.(*ast.SelectorExpr).Sel.Name != "NotTo" || !isMatcherNamed(matcher, "HaveOccurred") {
+		return nil, false
+	}
+
+	actual, ok := expect.Args[0].(*ast.Ident)
+	if !ok || actual.Name != err.Name {
+		return nil, false
+	}
+
+	// Succeed() prints the error, so a description that only formats the
+	// error (eg: "unexpected error: %v", err) is not needed
+	description := to.Args[1:]
+	if len(description) == 2 && countIdentifiers(description[1], err.Name) > 0 {
+		description = nil
+	}
+
+	for _, arg := range description {
+		if countIdentifiers(arg, err.Name) > 0 {
+			return nil, false
+		}
+	}
+
+	return createExpectation(ifStmt.Pos(), assignStmt.Rhs[0], false, createMatcher("Succeed"), description), true
+}
+
+func isMatcherNamed(matcher *ast.CallExpr, name string) bool {
+	ident, ok := matcher.Fun.(*ast.Ident)
+	return ok && ident.Name == name
+}
@@ -0,0 +1,203 @@
+package converter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+/*
+ * The same output comments that go/doc recognises, eg: // Output: hello
+ */
+var outputCommentRegexp = regexp.MustCompile(`(?i)^[[:space:]]*(unordered )?output:`)
+
+/*
+ * The name of the func that the converted examples run their body with,
+ * declared at the top of the container they are added to
+ */
+const exampleOutputHelper = "outputOf"
+
+/*
+ * Runs an example's body and returns what it printed to stdout, trimmed
+ * the same way `go test` trims it before comparing it with the example's
+ * output comment. The output goes to a file rather than a pipe, so that
+ * examples printing more than a pipe can buffer do not block.
+ */
+const exampleOutputHelperSource = `func(example func()) string {
+	file, err := os.CreateTemp("", "example")
+	Expect(err).NotTo(HaveOccurred())
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	stdout := os.Stdout
+	os.Stdout = file
+	func() {
+		defer func() { os.Stdout = stdout }()
+		example()
+	}()
+
+	output, err := os.ReadFile(file.Name())
+	Expect(err).NotTo(HaveOccurred())
+	return strings.TrimSpace(string(output))
+}`
+
+/*
+ * Converts examples into Its that check what they print, eg:
+ *   func ExampleGreet() {
+ *     Greet("world")
+ *     // Output: hello world
+ *   }
+ * becomes
+ *   It("ExampleGreet", func() {
+ *     Expect(outputOf(func() {
+ *       Greet("world")
+ *     })).To(Equal("hello world"))
+ *   })
+ * With an "Unordered output:" comment, the lines printed must ConsistOf
+ * the expected lines instead. `go test` only compiles examples without an
+ * output comment, so those are reported and left as they are.
+ */
+func (r *fileRewriter) convertExamples(describe *ast.ExprStmt) error {
+	examples := map[string]*doc.Example{}
+	for _, example := range doc.Examples(r.rootNode) {
+		examples["Example"+example.Name] = example
+	}
+
+	if len(examples) == 0 {
+		return nil
+	}
+
+	if countIdentifiers(r.rootNode, exampleOutputHelper) > 0 {
+		r.report(r.rootNode.Pos(), "the file already has something named %s; left its examples as they are", exampleOutputHelper)
+		return nil
+	}
+
+	converted := false
+	for _, decl := range append([]ast.Decl{}, r.rootNode.Decls...) {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || funcDecl.Recv != nil {
+			continue
+		}
+
+		example, ok := examples[funcDecl.Name.Name]
+		if !ok {
+			continue
+		}
+
+		if example.Output == "" && !example.EmptyOutput {
+			r.report(funcDecl.Pos(), "%s has no output comment, so `go test` only compiles it; left it as it is", funcDecl.Name.Name)
+			continue
+		}
+
+		r.removeOutputComment(funcDecl.Body)
+		name := &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(rewriteExampleName(funcDecl.Name.Name))}
+		spec := createGinkgoStatement("It", name, []ast.Stmt{exampleExpectation(funcDecl.Body, example)})
+		err := rewriteTestFuncAsItStatement(funcDecl, spec, "", r.rootNode, describe, r.target.backend)
+		if err != nil {
+			return err
+		}
+		converted = true
+	}
+
+	if !converted {
+		return nil
+	}
+
+	helper, err := parser.ParseExpr(exampleOutputHelperSource)
+	if err != nil {
+		return fmt.Errorf("Assert failed: Error parsing the examples' output helper:\n%s\n", err.Error())
+	}
+	clearPositions(helper)
+
+	block, err := blockStatementFromDescribe(describe)
+	if err != nil {
+		return err
+	}
+
+	declaration := &ast.AssignStmt{Lhs: []ast.Expr{&ast.Ident{Name: exampleOutputHelper}}, Tok: token.DEFINE, Rhs: []ast.Expr{helper}}
+	block.List = append([]ast.Stmt{declaration}, block.List...)
+	r.usesGomega = true
+
+	for _, path := range []string{"os", "strings"} {
+		addImport(r.rootNode, "", path)
+	}
+	return nil
+}
+
+/*
+ * eg: "ExampleSortingWords" becomes "sorting words", like tests are named.
+ * The examples of the package itself (eg: Example_sorting) keep their name.
+ */
+func rewriteExampleName(exampleName string) string {
+	name := strings.TrimPrefix(exampleName, "Example")
+	if name == "" || !unicode.IsUpper(rune(name[0])) {
+		return exampleName
+	}
+	return rewriteTestName("Test" + name)
+}
+
+/*
+ * The expected output becomes the example's assertion, so its comment goes
+ */
+func (r *fileRewriter) removeOutputComment(body *ast.BlockStmt) {
+	for index := len(r.rootNode.Comments) - 1; index >= 0; index-- {
+		group := r.rootNode.Comments[index]
+		if group.Pos() < body.Lbrace || group.End() > body.Rbrace {
+			continue
+		}
+
+		if outputCommentRegexp.MatchString(group.Text()) {
+			r.rootNode.Comments = append(r.rootNode.Comments[:index], r.rootNode.Comments[index+1:]...)
+		}
+		return
+	}
+}
+
+/*
+ * eg: Expect(outputOf(func() { ... })).To(Equal("hello world"))
+ */
+func exampleExpectation(body *ast.BlockStmt, example *doc.Example) ast.Stmt {
+	if len(body.List) > 0 {
+		// close the func right after its last statement, where the output comment was
+		body.Rbrace = body.List[len(body.List)-1].End()
+	}
+
+	funcLit := &ast.FuncLit{Type: &ast.FuncType{Params: &ast.FieldList{}}, Body: body}
+	var actual ast.Expr = &ast.CallExpr{Fun: &ast.Ident{Name: exampleOutputHelper}, Args: []ast.Expr{funcLit}}
+
+	expected := strings.TrimSpace(example.Output)
+	var matcher ast.Expr
+	switch {
+	case expected == "":
+		matcher = createMatcher("BeEmpty")
+	case example.Unordered:
+		lines := []ast.Expr{}
+		for _, line := range strings.Split(expected, "\n") {
+			lines = append(lines, stringLiteral(line))
+		}
+
+		split := &ast.SelectorExpr{X: &ast.Ident{Name: "strings"}, Sel: &ast.Ident{Name: "Split"}}
+		actual = &ast.CallExpr{Fun: split, Args: []ast.Expr{actual, &ast.BasicLit{Kind: token.STRING, Value: `"\n"`}}}
+		matcher = createMatcher("ConsistOf", lines...)
+	default:
+		matcher = createMatcher("Equal", stringLiteral(expected))
+	}
+	return createExpectation(body.Lbrace, actual, false, matcher, nil)
+}
+
+/*
+ * A string literal for value, using a raw string for text spanning
+ * several lines
+ */
+func stringLiteral(value string) *ast.BasicLit {
+	if strings.Contains(value, "\n") && !strings.Contains(value, "`") && !strings.Contains(value, "\r") {
+		return &ast.BasicLit{Kind: token.STRING, Value: "`" + value + "`"}
+	}
+	return &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(value)}
+}
@@ -1,8 +1,10 @@
-package main
+package converter
 
 import (
+	"errors"
 	"fmt"
 	"go/ast"
+	"go/token"
 	"strings"
 	"unicode"
 )
@@ -19,6 +21,17 @@ func createInitBlock() *ast.FuncDecl {
 	return &ast.FuncDecl{Name: ident, Type: funcType, Body: blockStatement}
 }
 
+/*
+ * Wraps a container in a top level var _ = Describe(...) declaration
+ */
+func createTopLevelContainer(describe *ast.ExprStmt) *ast.GenDecl {
+	valueSpec := &ast.ValueSpec{
+		Names:  []*ast.Ident{{Name: "_"}},
+		Values: []ast.Expr{describe.X},
+	}
+	return &ast.GenDecl{Tok: token.VAR, Specs: []ast.Spec{valueSpec}}
+}
+
 /*
  * Creates a Describe("Testing with ginkgo", func() { }) node
  */
@@ -42,13 +55,17 @@ func createDescribeBlock() *ast.ExprStmt {
  * body of the function with a GinktoT.
  */
 func namedTestingTArg(node *ast.FuncDecl) string {
-	return node.Type.Params.List[0].Names[0].Name // *exhale*
+	names := node.Type.Params.List[0].Names
+	if len(names) == 0 {
+		return "" // eg: func TestFoo(*testing.T) never refers to its T
+	}
+	return names[0].Name // *exhale*
 }
 
 /*
  * Convenience function to return the block statement node for a Describe statement
  */
-func blockStatementFromDescribe(desc *ast.ExprStmt) *ast.BlockStmt {
+func blockStatementFromDescribe(desc *ast.ExprStmt) (*ast.BlockStmt, error) {
 	var funcLit *ast.FuncLit
 	var found = false
 
@@ -62,10 +79,10 @@ func blockStatementFromDescribe(desc *ast.ExprStmt) *ast.BlockStmt {
 	}
 
 	if !found {
-		panic("Error finding ast.FuncLit inside describe statement. Somebody done goofed.")
+		return nil, errors.New("Error finding ast.FuncLit inside describe statement. Somebody done goofed.")
 	}
 
-	return funcLit.Body
+	return funcLit.Body, nil
 }
 
 /* convenience function for creating an It("TestNameHere")
@@ -73,14 +90,28 @@ func blockStatementFromDescribe(desc *ast.ExprStmt) *ast.BlockStmt {
  * func passed to It()
  */
 func createItStatementForTestFunc(testFunc *ast.FuncDecl) *ast.ExprStmt {
-	blockStatement := &ast.BlockStmt{List: testFunc.Body.List}
+	humanReadableName := rewriteTestName(testFunc.Name.Name)
+	basicLit := &ast.BasicLit{Kind: 9, Value: fmt.Sprintf("\"%s\"", humanReadableName)}
+	return createGinkgoStatement("It", basicLit, testFunc.Body.List)
+}
+
+/*
+ * Creates a ginkgo node such as It("name", func() { statements })
+ * or Context("name", func() { statements }).
+ * A nil name creates a node without one, eg: BeforeEach(func() {})
+ */
+func createGinkgoStatement(kind string, name ast.Expr, statements []ast.Stmt) *ast.ExprStmt {
+	blockStatement := &ast.BlockStmt{List: statements}
 	fieldList := &ast.FieldList{}
 	funcType := &ast.FuncType{Params: fieldList}
 	funcLit := &ast.FuncLit{Type: funcType, Body: blockStatement}
-	humanReadableName := rewriteTestName(testFunc.Name.Name)
-	basicLit := &ast.BasicLit{Kind: 9, Value: fmt.Sprintf("\"%s\"", humanReadableName)}
-	itBlockIdent := &ast.Ident{Name: "It"}
-	callExpr := &ast.CallExpr{Fun: itBlockIdent, Args: []ast.Expr{basicLit, funcLit}}
+
+	args := []ast.Expr{funcLit}
+	if name != nil {
+		args = append([]ast.Expr{name}, args...)
+	}
+
+	callExpr := &ast.CallExpr{Fun: &ast.Ident{Name: kind}, Args: args}
 	return &ast.ExprStmt{X: callExpr}
 }
 
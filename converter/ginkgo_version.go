@@ -0,0 +1,68 @@
+package converter
+
+import (
+	"fmt"
+)
+
+/*
+ * A ginkgoTarget describes the idioms of the version of ginkgo we convert to
+ */
+type ginkgoTarget struct {
+	importPath    string
+	suiteTemplate string
+	backend       TestingTBackend
+
+	// where DescribeTable and Entry come from, when it is not importPath
+	tableImportPath string
+
+	// v1 registers its containers inside a func init() {}, while v2 uses
+	// top level var _ = Describe(...) declarations
+	useInitFunc bool
+
+	// v2 has DeferCleanup and GinkgoHelper to stand in for t.Cleanup and t.Helper
+	hasCleanupAndHelper bool
+
+	// v2 has the Labels and report entries that benchmarks are converted with
+	hasMeasurements bool
+}
+
+var ginkgoV1 = ginkgoTarget{
+	importPath:      "github.com/onsi/ginkgo",
+	suiteTemplate:   defaultSuiteTemplate,
+	backend:         MrTBackend,
+	tableImportPath: "github.com/onsi/ginkgo/extensions/table",
+	useInitFunc:     true,
+}
+
+var ginkgoV2 = ginkgoTarget{
+	importPath:          "github.com/onsi/ginkgo/v2",
+	suiteTemplate:       defaultV2SuiteTemplate,
+	backend:             GinkgoTBackend,
+	hasCleanupAndHelper: true,
+	hasMeasurements:     true,
+}
+
+/*
+ * The ginkgo version selected by the converter's options, using the
+ * testing.T backend they name instead of the version's default
+ */
+func (c *Converter) target() (ginkgoTarget, error) {
+	var target ginkgoTarget
+	switch c.options.GinkgoVersion {
+	case "", "v1":
+		target = ginkgoV1
+	case "v2":
+		target = ginkgoV2
+	default:
+		return target, fmt.Errorf("unknown ginkgo version '%s', expected v1 or v2\n", c.options.GinkgoVersion)
+	}
+
+	if c.options.TestingT != nil {
+		err := c.options.TestingT.validate()
+		if err != nil {
+			return target, err
+		}
+		target.backend = *c.options.TestingT
+	}
+	return target, nil
+}
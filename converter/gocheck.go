@@ -0,0 +1,460 @@
+package converter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+const gocheckImportPath = "gopkg.in/check.v1"
+
+var gocheckSuiteStyle = suiteStyle{
+	hooks: map[string]string{
+		"SetUpSuite":    "BeforeAll",
+		"SetUpTest":     "BeforeEach",
+		"TearDownTest":  "AfterEach",
+		"TearDownSuite": "AfterAll",
+	},
+	unsupported: func(name string) bool {
+		return strings.HasPrefix(name, "Benchmark")
+	},
+}
+
+/*
+ * The methods of gocheck's *C that *testing.T has too
+ */
+var gocheckTMethods = map[string]bool{
+	"Error":   true,
+	"Errorf":  true,
+	"Fatal":   true,
+	"Fatalf":  true,
+	"Fail":    true,
+	"FailNow": true,
+	"Failed":  true,
+	"Log":     true,
+	"Logf":    true,
+	"Skip":    true,
+}
+
+/*
+ * The exported names of gocheck, to tell whether a dot import of it is
+ * still used
+ */
+var gocheckNames = []string{
+	"C", "Suite", "TestingT", "Commentf", "Not", "Checker", "CheckerInfo",
+	"Equals", "DeepEquals", "IsNil", "NotNil", "ErrorMatches", "HasLen",
+	"Matches", "Panics", "PanicMatches", "FitsTypeOf", "Implements",
+}
+
+var gocheckCheckers = map[string]matcherMapping{
+	"Equals":     {1, mapsOnto(false, "Equal")},
+	"DeepEquals": {1, mapsOnto(false, "Equal")},
+	"HasLen":     {1, mapsOnto(false, "HaveLen")},
+	"IsNil":      {0, mapsOntoNilCheck(false)},
+	"NotNil":     {0, mapsOntoNilCheck(true)},
+	"ErrorMatches": {1, func(obtained ast.Expr, args []ast.Expr) (bool, ast.Expr) {
+		return false, createMatcher("MatchError", createMatcher("MatchRegexp", anchoredPattern(args[0])))
+	}},
+}
+
+/*
+ * gocheck matches the whole error message, eg: ErrorMatches, "not found"
+ * matches "^not found$"
+ */
+func anchoredPattern(pattern ast.Expr) ast.Expr {
+	if literal, ok := pattern.(*ast.BasicLit); ok && literal.Kind == token.STRING {
+		quote, text := literal.Value[:1], literal.Value[1:len(literal.Value)-1]
+		return &ast.BasicLit{Kind: token.STRING, Value: quote + "^" + text + "$" + quote}
+	}
+
+	start := &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote("^")}
+	end := &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote("$")}
+	withStart := &ast.BinaryExpr{X: start, Op: token.ADD, Y: pattern}
+	return &ast.BinaryExpr{X: withStart, Op: token.ADD, Y: end}
+}
+
+/*
+ * Converts the gocheck suites registered in this file, eg:
+ *   var _ = Suite(&DatabaseSuite{})
+ * into Describes, which are added to the file's container. The fixtures
+ * SetUpTest and TearDownTest become a BeforeEach and AfterEach, SetUpSuite
+ * and TearDownSuite a BeforeAll and AfterAll, and each TestXxx(c *C) method
+ * an It. c.Assert and c.Check become gomega assertions. Once every suite is
+ * converted, the func Test(t *testing.T) { TestingT(t) } that hands the
+ * package's tests to gocheck is removed. Suites that cannot be converted
+ * are reported and left as they are.
+ */
+func (r *fileRewriter) convertGocheckSuites(describe *ast.ExprStmt) error {
+	gocheckPackage, ok := importedName(r.rootNode, gocheckImportPath, "check")
+	if !ok {
+		return nil
+	}
+
+	block, err := blockStatementFromDescribe(describe)
+	if err != nil {
+		return err
+	}
+
+	style := gocheckSuiteStyle
+	style.params = func(params *ast.FieldList) bool {
+		return isGocheckCParam(params, gocheckPackage)
+	}
+
+	converted := true
+	for _, decl := range r.rootNode.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.VAR {
+			continue
+		}
+
+		for _, spec := range append([]ast.Spec{}, genDecl.Specs...) {
+			value, ok := gocheckRegistration(spec, gocheckPackage)
+			if !ok {
+				continue
+			}
+
+			testSuite, ok := r.convertGocheckSuite(value, style, gocheckPackage)
+			if !ok {
+				converted = false
+				continue
+			}
+
+			block.List = append(block.List, testSuite)
+			r.removeSpec(genDecl, spec)
+		}
+	}
+
+	if converted {
+		for _, decl := range r.rootNode.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if ok && isGocheckEntryPoint(funcDecl, gocheckPackage) {
+				r.removeDecls(funcDecl)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func (r *fileRewriter) convertGocheckSuite(value ast.Expr, style suiteStyle, gocheckPackage string) (*ast.ExprStmt, bool) {
+	typeName, values, reason := suiteValue(value)
+	if reason == "" {
+		var testSuite xunitSuite
+		testSuite, reason = r.findSuite(typeName, style)
+		testSuite.values = values
+		if reason == "" && len(testSuite.embedded) > 0 {
+			reason = fmt.Sprintf("%s embeds other types", typeName)
+		}
+
+		if reason == "" {
+			return r.createGocheckDescribe(testSuite, gocheckPackage)
+		}
+	}
+
+	r.report(value.Pos(), "could not convert the gocheck suite: %s; left it as it is", reason)
+	return nil, false
+}
+
+func (r *fileRewriter) createGocheckDescribe(testSuite xunitSuite, gocheckPackage string) (*ast.ExprStmt, bool) {
+	typeName := testSuite.typeSpec.Name.Name
+	methods := []suiteMethod{}
+	for _, decl := range testSuite.methods {
+		method, reason := r.checkGocheckSuiteMethod(testSuite, decl, gocheckPackage)
+		if reason != "" {
+			r.report(decl.Pos(), "could not convert %s.%s: %s; left the suite as it is", typeName, decl.Name.Name, reason)
+			return nil, false
+		}
+		methods = append(methods, method)
+	}
+
+	for _, method := range methods {
+		r.reportStricterChecks(method)
+		r.rewriteSuiteMethod(testSuite, method)
+		r.replaceGocheckC(method)
+	}
+
+	// named like the testify suites, after the test running them, eg: "config suite"
+	name := &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(rewriteTestName("Test" + typeName))}
+	return r.createSuiteDescribe(testSuite, name), true
+}
+
+/*
+ * Besides the suite's fields and helpers, the tests and fixtures of a
+ * gocheck suite can use their *C to make assertions with c.Assert and
+ * c.Check, and to call the methods it shares with *testing.T
+ */
+func (r *fileRewriter) checkGocheckSuiteMethod(testSuite xunitSuite, decl *ast.FuncDecl, gocheckPackage string) (method suiteMethod, reason string) {
+	method, reason = newSuiteMethod(testSuite, decl)
+	if reason != "" {
+		return method, reason
+	}
+
+	names := map[string]bool{}
+	if method.receiver != "" {
+		names[method.receiver] = true
+	}
+
+	c := gocheckCName(decl)
+	if c == "" || !(testSuite.isTest(decl) || testSuite.isHook(decl)) {
+		return method, method.unconvertedUse(names)
+	}
+	names[c] = true
+
+	ast.Inspect(decl.Body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.ExprStmt:
+			callExpr, ok := node.X.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+
+			ident, function, ok := methodCall(callExpr, c)
+			if !ok || (function != "Assert" && function != "Check") {
+				return true
+			}
+
+			expectation, why := gocheckAssertionAsExpectation(callExpr, gocheckPackage)
+			if why != "" {
+				if reason == "" {
+					reason = why
+				}
+				return true
+			}
+			method.replacements[node] = expectation
+			method.converted[ident] = true
+
+		case *ast.CallExpr:
+			if ident, function, ok := methodCall(node, c); ok && gocheckTMethods[function] {
+				method.converted[ident] = true
+			}
+		}
+		return true
+	})
+
+	if reason != "" {
+		return method, reason
+	}
+	return method, method.unconvertedUse(names)
+}
+
+/*
+ * Turns c.Assert(obtained, Checker, args..., [Commentf(...)]) into an
+ * expectation, or returns why it cannot. Checkers can be negated with Not.
+ */
+func gocheckAssertionAsExpectation(callExpr *ast.CallExpr, gocheckPackage string) (*ast.ExprStmt, string) {
+	if len(callExpr.Args) < 2 {
+		return nil, "this assertion has no checker"
+	}
+
+	obtained, checkerExpr, args := callExpr.Args[0], callExpr.Args[1], callExpr.Args[2:]
+
+	negated := false
+	if notArgs, ok := gocheckCallArgs(checkerExpr, gocheckPackage, "Not"); ok && len(notArgs) == 1 {
+		negated, checkerExpr = true, notArgs[0]
+	}
+
+	checkerName, ok := gocheckIdent(checkerExpr, gocheckPackage)
+	checker, known := gocheckCheckers[checkerName]
+	if !ok || !known {
+		return nil, "this checker has no gomega equivalent"
+	}
+
+	var description []ast.Expr
+	if len(args) == checker.args+1 {
+		commentArgs, ok := gocheckCallArgs(args[checker.args], gocheckPackage, "Commentf")
+		if !ok {
+			return nil, "the comment of this assertion must be a call to Commentf"
+		}
+		description, args = commentArgs, args[:checker.args]
+	}
+
+	if len(args) != checker.args {
+		return nil, fmt.Sprintf("%s takes %d args", checkerName, checker.args)
+	}
+
+	matcherNegated, matcher := checker.build(obtained, args)
+	return createExpectation(callExpr.Pos(), obtained, negated != matcherNegated, matcher, description), ""
+}
+
+/*
+ * c.Check carries on with the test when it fails, like testify's assert,
+ * but the expectation it becomes ends the spec. Reported once per block,
+ * for the first c.Check followed by more of the block.
+ */
+func (r *fileRewriter) reportStricterChecks(method suiteMethod) {
+	c := gocheckCName(method.decl)
+	if c == "" {
+		return
+	}
+
+	ast.Inspect(method.decl.Body, func(node ast.Node) bool {
+		block, ok := node.(*ast.BlockStmt)
+		if !ok {
+			return true
+		}
+
+		for index, statement := range block.List {
+			if _, ok := method.replacements[statement]; !ok || index == len(block.List)-1 {
+				continue
+			}
+
+			callExpr := statement.(*ast.ExprStmt).X.(*ast.CallExpr)
+			if _, function, _ := methodCall(callExpr, c); function == "Check" {
+				r.report(callExpr.Pos(), "%s.Check and the checks after it now end the spec when they fail, where gocheck would carry on with the test", c)
+				break
+			}
+		}
+		return true
+	})
+}
+
+/*
+ * c.Log(...), c.Fatal(...) and the other methods *C shares with
+ * *testing.T are called on whatever replaces *testing.T, eg: mr.T().Log(...)
+ */
+func (r *fileRewriter) replaceGocheckC(method suiteMethod) {
+	c := gocheckCName(method.decl)
+	if c == "" {
+		return
+	}
+
+	ast.Inspect(method.decl.Body, func(node ast.Node) bool {
+		callExpr, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+
+		if ident, function, ok := methodCall(callExpr, c); ok && gocheckTMethods[function] {
+			callExpr.Fun.(*ast.SelectorExpr).X = r.target.backend.newTFromIdent(ident)
+		}
+		return true
+	})
+}
+
+/*
+ * eg: var _ = Suite(&DatabaseSuite{}), returning &DatabaseSuite{}
+ */
+func gocheckRegistration(spec ast.Spec, gocheckPackage string) (ast.Expr, bool) {
+	valueSpec, ok := spec.(*ast.ValueSpec)
+	if !ok || len(valueSpec.Names) != 1 || valueSpec.Names[0].Name != "_" || len(valueSpec.Values) != 1 {
+		return nil, false
+	}
+
+	args, ok := gocheckCallArgs(valueSpec.Values[0], gocheckPackage, "Suite")
+	if !ok || len(args) != 1 {
+		return nil, false
+	}
+	return args[0], true
+}
+
+/*
+ * eg: func Test(t *testing.T) { TestingT(t) }
+ */
+func isGocheckEntryPoint(decl *ast.FuncDecl, gocheckPackage string) bool {
+	if decl.Recv != nil || decl.Body == nil || len(decl.Body.List) != 1 || !receivesTestingT(decl) {
+		return false
+	}
+
+	exprStmt, ok := decl.Body.List[0].(*ast.ExprStmt)
+	if !ok {
+		return false
+	}
+
+	_, ok = gocheckCallArgs(exprStmt.X, gocheckPackage, "TestingT")
+	return ok
+}
+
+/*
+ * Whether params are the (c *C) that gocheck passes to tests and fixtures
+ */
+func isGocheckCParam(params *ast.FieldList, gocheckPackage string) bool {
+	if params.NumFields() != 1 {
+		return false
+	}
+
+	starExpr, ok := params.List[0].Type.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+
+	name, ok := gocheckIdent(starExpr.X, gocheckPackage)
+	return ok && name == "C"
+}
+
+/*
+ * The name of a test or fixture's *C, eg: c
+ */
+func gocheckCName(decl *ast.FuncDecl) string {
+	params := decl.Type.Params
+	if params.NumFields() != 1 || len(params.List[0].Names) != 1 || params.List[0].Names[0].Name == "_" {
+		return ""
+	}
+	return params.List[0].Names[0].Name
+}
+
+/*
+ * The name of one of gocheck's exports, eg: Equals or check.Equals
+ */
+func gocheckIdent(expr ast.Expr, gocheckPackage string) (string, bool) {
+	if gocheckPackage == "." {
+		ident, ok := expr.(*ast.Ident)
+		if !ok {
+			return "", false
+		}
+		return ident.Name, true
+	}
+
+	selectorExpr, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return "", false
+	}
+
+	ident, ok := selectorExpr.X.(*ast.Ident)
+	return selectorExpr.Sel.Name, ok && ident.Name == gocheckPackage
+}
+
+/*
+ * The args of a call to one of gocheck's funcs, eg: Commentf("x = %d", x)
+ */
+func gocheckCallArgs(expr ast.Expr, gocheckPackage, name string) ([]ast.Expr, bool) {
+	callExpr, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return nil, false
+	}
+
+	funcName, ok := gocheckIdent(callExpr.Fun, gocheckPackage)
+	return callExpr.Args, ok && funcName == name
+}
+
+/*
+ * eg: c.Assert(...) returns c and "Assert"
+ */
+func methodCall(callExpr *ast.CallExpr, receiver string) (*ast.Ident, string, bool) {
+	selectorExpr, ok := callExpr.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return nil, "", false
+	}
+
+	ident, ok := selectorExpr.X.(*ast.Ident)
+	return ident, selectorExpr.Sel.Name, ok && ident.Name == receiver
+}
+
+/*
+ * Removes a spec from a declaration, or the whole declaration when it
+ * was the only one
+ */
+func (r *fileRewriter) removeSpec(genDecl *ast.GenDecl, spec ast.Spec) {
+	if len(genDecl.Specs) == 1 {
+		r.removeDecls(genDecl)
+		return
+	}
+
+	for index, other := range genDecl.Specs {
+		if other == spec {
+			genDecl.Specs = append(genDecl.Specs[:index], genDecl.Specs[index+1:]...)
+			return
+		}
+	}
+}
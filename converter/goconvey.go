@@ -0,0 +1,491 @@
+package converter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+)
+
+const goConveyImportPath = "github.com/smartystreets/goconvey/convey"
+
+var goConveyAssertions = map[string]matcherMapping{
+	"ShouldEqual":                  {1, mapsOntoEquality(false)},
+	"ShouldNotEqual":               {1, mapsOntoEquality(true)},
+	"ShouldResemble":               {1, mapsOnto(false, "Equal")},
+	"ShouldNotResemble":            {1, mapsOnto(true, "Equal")},
+	"ShouldBeNil":                  {0, mapsOntoNilCheck(false)},
+	"ShouldNotBeNil":               {0, mapsOntoNilCheck(true)},
+	"ShouldBeError":                {0, mapsOnto(false, "HaveOccurred")},
+	"ShouldBeTrue":                 {0, mapsOnto(false, "BeTrue")},
+	"ShouldBeFalse":                {0, mapsOnto(false, "BeFalse")},
+	"ShouldBeZeroValue":            {0, mapsOnto(false, "BeZero")},
+	"ShouldNotBeZeroValue":         {0, mapsOnto(true, "BeZero")},
+	"ShouldBeGreaterThan":          {1, mapsOntoComparison(">")},
+	"ShouldBeGreaterThanOrEqualTo": {1, mapsOntoComparison(">=")},
+	"ShouldBeLessThan":             {1, mapsOntoComparison("<")},
+	"ShouldBeLessThanOrEqualTo":    {1, mapsOntoComparison("<=")},
+	"ShouldAlmostEqual":            {2, mapsOntoComparison("~")},
+	"ShouldContain":                {1, mapsOnto(false, "ContainElement")},
+	"ShouldNotContain":             {1, mapsOnto(true, "ContainElement")},
+	"ShouldContainKey":             {1, mapsOnto(false, "HaveKey")},
+	"ShouldNotContainKey":          {1, mapsOnto(true, "HaveKey")},
+	"ShouldContainSubstring":       {1, mapsOnto(false, "ContainSubstring")},
+	"ShouldNotContainSubstring":    {1, mapsOnto(true, "ContainSubstring")},
+	"ShouldStartWith":              {1, mapsOnto(false, "HavePrefix")},
+	"ShouldNotStartWith":           {1, mapsOnto(true, "HavePrefix")},
+	"ShouldEndWith":                {1, mapsOnto(false, "HaveSuffix")},
+	"ShouldNotEndWith":             {1, mapsOnto(true, "HaveSuffix")},
+	"ShouldBeEmpty":                {0, mapsOnto(false, "BeEmpty")},
+	"ShouldNotBeEmpty":             {0, mapsOnto(true, "BeEmpty")},
+	"ShouldHaveLength":             {1, mapsOnto(false, "HaveLen")},
+	"ShouldPanic":                  {0, mapsOnto(false, "Panic")},
+	"ShouldNotPanic":               {0, mapsOnto(true, "Panic")},
+	"ShouldHaveSameTypeAs":         {1, mapsOnto(false, "BeAssignableToTypeOf")},
+}
+
+/*
+ * The names GoConvey exports, to tell whether a dot import of it is still
+ * used: its funcs, and every assertion (not only those converted)
+ */
+var goConveyNames = []string{
+	"Convey", "FocusConvey", "SkipConvey", "So", "SoMsg", "SkipSo", "Reset", "C",
+	"FailureHalts", "FailureContinues", "FailureInherits", "StackError", "StackFail",
+	"Print", "Printf", "Println", "SetDefaultFailureMode",
+	"ShouldEqual", "ShouldNotEqual", "ShouldAlmostEqual", "ShouldNotAlmostEqual",
+	"ShouldResemble", "ShouldNotResemble", "ShouldPointTo", "ShouldNotPointTo",
+	"ShouldBeNil", "ShouldNotBeNil", "ShouldBeTrue", "ShouldBeFalse",
+	"ShouldBeZeroValue", "ShouldNotBeZeroValue", "ShouldBeGreaterThan",
+	"ShouldBeGreaterThanOrEqualTo", "ShouldBeLessThan", "ShouldBeLessThanOrEqualTo",
+	"ShouldBeBetween", "ShouldNotBeBetween", "ShouldBeBetweenOrEqual",
+	"ShouldNotBeBetweenOrEqual", "ShouldContain", "ShouldNotContain",
+	"ShouldContainKey", "ShouldNotContainKey", "ShouldBeIn", "ShouldNotBeIn",
+	"ShouldBeEmpty", "ShouldNotBeEmpty", "ShouldHaveLength", "ShouldStartWith",
+	"ShouldNotStartWith", "ShouldEndWith", "ShouldNotEndWith", "ShouldBeBlank",
+	"ShouldNotBeBlank", "ShouldContainSubstring", "ShouldNotContainSubstring",
+	"ShouldEqualWithout", "ShouldEqualTrimSpace", "ShouldPanic", "ShouldNotPanic",
+	"ShouldPanicWith", "ShouldNotPanicWith", "ShouldHaveSameTypeAs",
+	"ShouldNotHaveSameTypeAs", "ShouldImplement", "ShouldNotImplement",
+	"ShouldHappenBefore", "ShouldHappenOnOrBefore", "ShouldHappenAfter",
+	"ShouldHappenOnOrAfter", "ShouldHappenBetween", "ShouldHappenOnOrBetween",
+	"ShouldNotHappenOnOrBetween", "ShouldHappenWithin", "ShouldNotHappenWithin",
+	"ShouldBeChronological", "ShouldBeError", "ShouldWrap",
+}
+
+/*
+ * The ginkgo nodes that a Convey, FocusConvey and SkipConvey become
+ * are prefixed with nothing, F (focused) and P (pending)
+ */
+var conveyPrefixes = map[string]string{
+	"Convey":      "",
+	"FocusConvey": "F",
+	"SkipConvey":  "P",
+}
+
+/*
+ * ShouldEqual compares numbers whatever their type, which BeNumerically
+ * does too, eg: So(count, ShouldEqual, 2) passes when count is an int64
+ */
+func mapsOntoEquality(negated bool) func(ast.Expr, []ast.Expr) (bool, ast.Expr) {
+	return func(actual ast.Expr, args []ast.Expr) (bool, ast.Expr) {
+		if isNumericLiteral(args[0]) {
+			return negated, createNumericMatcher("==", args[0])
+		}
+		return negated, createMatcher("Equal", args[0])
+	}
+}
+
+func mapsOntoComparison(comparator string) func(ast.Expr, []ast.Expr) (bool, ast.Expr) {
+	return func(actual ast.Expr, args []ast.Expr) (bool, ast.Expr) {
+		matcher := createNumericMatcher(comparator, args[0])
+		matcher.Args = append(matcher.Args, args[1:]...)
+		return false, matcher
+	}
+}
+
+/*
+ * A Convey block, eg: Convey("Given a stack", t, func() { ... })
+ */
+type conveyBlock struct {
+	pos    token.Pos
+	name   ast.Expr
+	prefix string
+	body   *ast.BlockStmt
+}
+
+/*
+ * The names that GoConvey's funcs are called with in a test: either the
+ * package (or nothing, when it is dot imported), or the C passed to a
+ * Convey's func, eg: Convey("...", func(c C) { c.So(...) })
+ */
+type goConveyNaming struct {
+	packageName string
+	contexts    map[string]bool
+}
+
+/*
+ * Converts a test made of Convey blocks: the outermost Convey becomes a
+ * Describe, Conveys that hold further Conveys become Contexts, and the
+ * innermost ones become Its. GoConvey runs a Convey's statements again
+ * before each of the Conveys inside it, so they become a BeforeEach (with
+ * the variables they declare moved into the container), and Reset blocks
+ * and any statements after the inner Conveys become AfterEachs.
+ * So assertions become gomega assertions. ok is false when the test has
+ * no Convey blocks, or when they cannot be converted (reporting why).
+ */
+func (r *fileRewriter) createSpecForGoConvey(testFunc *ast.FuncDecl, name *ast.BasicLit) (ast.Stmt, bool) {
+	if !r.converter.options.GoConvey {
+		return nil, false
+	}
+
+	packageName, ok := importedName(r.rootNode, goConveyImportPath, "convey")
+	if !ok {
+		return nil, false
+	}
+
+	naming := goConveyNaming{packageName: packageName, contexts: map[string]bool{}}
+	ast.Inspect(testFunc.Body, func(node ast.Node) bool {
+		if funcLit, ok := node.(*ast.FuncLit); ok {
+			if context, ok := naming.contextParam(funcLit); ok {
+				naming.contexts[context] = true
+			}
+		}
+		return true
+	})
+
+	testingT := namedTestingTArg(testFunc)
+	hasConvey := false
+	for _, statement := range testFunc.Body.List {
+		if _, ok := naming.conveyCall(statement); ok {
+			hasConvey = true
+		}
+	}
+
+	if !hasConvey {
+		return nil, false
+	}
+
+	assertions, ok := r.soAssertions(testFunc.Body, naming)
+	if !ok || !r.checkConveyContexts(testFunc.Body, naming) {
+		return nil, false
+	}
+
+	setup, resets, blocks, teardown, ok := r.splitConveyBody(testFunc.Body, naming, testingT)
+	if !ok {
+		return nil, false
+	}
+
+	var spec ast.Stmt
+	if len(setup) == 0 && len(resets) == 0 && len(teardown) == 0 && len(blocks) == 1 {
+		spec, ok = r.createSpecForConveyBlock(blocks[0], "Describe", naming, "")
+	} else {
+		spec, ok = r.createConveyContainer("Describe", name, setup, resets, teardown, blocks, "Describe", naming)
+	}
+
+	if !ok {
+		return nil, false
+	}
+
+	replaceStatements(spec, assertions)
+	r.usesGomega = r.usesGomega || len(assertions) > 0
+	return spec, true
+}
+
+/*
+ * The spec for a Convey: an It when it holds no other Conveys, and a
+ * container of kind otherwise
+ */
+func (r *fileRewriter) createSpecForConveyBlock(block conveyBlock, kind string, naming goConveyNaming, testingT string) (ast.Stmt, bool) {
+	setup, resets, blocks, teardown, ok := r.splitConveyBody(block.body, naming, testingT)
+	if !ok {
+		return nil, false
+	}
+
+	if len(blocks) == 0 {
+		if len(resets) > 0 {
+			r.report(block.body.Pos(), "a Reset in a Convey without nested Conveys has no ginkgo equivalent; left the test as a single It")
+			return nil, false
+		}
+
+		spec := createGinkgoStatement(block.prefix+"It", block.name, setup)
+		positionSpecAt(spec, block.pos, block.body)
+		return spec, true
+	}
+
+	spec, ok := r.createConveyContainer(block.prefix+kind, block.name, setup, resets, teardown, blocks, "Context", naming)
+	if ok {
+		positionSpecAt(spec, block.pos, block.body)
+	}
+	return spec, ok
+}
+
+func (r *fileRewriter) createConveyContainer(kind string, name ast.Expr, setup []ast.Stmt, resets []*ast.BlockStmt, teardown []ast.Stmt, blocks []conveyBlock, childKind string, naming goConveyNaming) (ast.Stmt, bool) {
+	statements, ok := r.createSetupNodes(setup)
+	if !ok {
+		return nil, false
+	}
+
+	if len(teardown) > 0 {
+		statements = append(statements, createGinkgoStatement("AfterEach", nil, teardown))
+	}
+
+	for _, reset := range resets {
+		statements = append(statements, createGinkgoStatement("AfterEach", nil, reset.List))
+	}
+
+	for _, block := range blocks {
+		spec, ok := r.createSpecForConveyBlock(block, childKind, naming, "")
+		if !ok {
+			return nil, false
+		}
+		statements = append(statements, spec)
+	}
+	return createGinkgoStatement(kind, name, statements), true
+}
+
+/*
+ * Splits the statements of a Convey (or a test) into those that come
+ * before its nested Conveys, its Reset blocks, the nested Conveys and the
+ * statements after them. Statements between two nested Conveys run at a
+ * point that no ginkgo node matches, so they cannot be converted.
+ */
+func (r *fileRewriter) splitConveyBody(body *ast.BlockStmt, naming goConveyNaming, testingT string) (setup []ast.Stmt, resets []*ast.BlockStmt, blocks []conveyBlock, teardown []ast.Stmt, ok bool) {
+	for _, statement := range body.List {
+		if call, ok := naming.conveyCall(statement); ok {
+			if len(teardown) > 0 {
+				r.report(teardown[0].Pos(), "statements between two Convey blocks cannot be converted; left the test as a single It")
+				return nil, nil, nil, nil, false
+			}
+
+			block, ok := r.conveyBlockForCall(call, naming, testingT)
+			if !ok {
+				return nil, nil, nil, nil, false
+			}
+			blocks = append(blocks, block)
+			continue
+		}
+
+		if reset, ok := naming.resetBody(statement); ok {
+			resets = append(resets, reset)
+			continue
+		}
+
+		if len(blocks) == 0 {
+			setup = append(setup, statement)
+		} else {
+			teardown = append(teardown, statement)
+		}
+	}
+	return setup, resets, blocks, teardown, true
+}
+
+/*
+ * eg: Convey("Given a stack", t, func() { ... }). The outermost Conveys
+ * are passed the test's t, which is what testingT is given for.
+ */
+func (r *fileRewriter) conveyBlockForCall(call *ast.CallExpr, naming goConveyNaming, testingT string) (block conveyBlock, ok bool) {
+	function, _ := naming.function(call.Fun)
+	block.pos, block.prefix = call.Pos(), conveyPrefixes[function]
+
+	args := call.Args
+	if len(args) < 2 {
+		r.report(call.Pos(), "a Convey without a func cannot be converted; left the test as a single It")
+		return block, false
+	}
+	block.name, args = args[0], args[1:]
+
+	if testingT != "" {
+		if ident, ok := args[0].(*ast.Ident); !ok || ident.Name != testingT || len(args) < 2 {
+			r.report(call.Pos(), "the outermost Convey blocks must be passed the test's %s; left the test as a single It", testingT)
+			return block, false
+		}
+		args = args[1:]
+	}
+
+	funcLit, ok := args[len(args)-1].(*ast.FuncLit)
+	if !ok || len(args) != 1 {
+		r.report(call.Pos(), "only Convey blocks with a name and a func literal (and no failure mode) can be converted; left the test as a single It")
+		return block, false
+	}
+
+	block.body = funcLit.Body
+	return block, true
+}
+
+/*
+ * Finds the So assertions in a test, and the gomega assertions that
+ * replace them. ok is false (reporting why) when one of them cannot be
+ * converted, or its result is used.
+ */
+func (r *fileRewriter) soAssertions(body *ast.BlockStmt, naming goConveyNaming) (map[ast.Stmt]ast.Stmt, bool) {
+	assertions := map[ast.Stmt]ast.Stmt{}
+	statements := map[*ast.CallExpr]bool{}
+	ok := true
+	ast.Inspect(body, func(node ast.Node) bool {
+		if !ok {
+			return false
+		}
+
+		switch node := node.(type) {
+		case *ast.ExprStmt:
+			callExpr, isCall := node.X.(*ast.CallExpr)
+			if !isCall {
+				return true
+			}
+
+			if function, _ := naming.function(callExpr.Fun); function != "So" {
+				return true
+			}
+			statements[callExpr] = true
+
+			expectation, reason := naming.soAsExpectation(callExpr)
+			if reason != "" {
+				r.report(callExpr.Pos(), "could not convert this So assertion: %s; left the test as a single It", reason)
+				ok = false
+				return false
+			}
+			assertions[node] = expectation
+
+		case *ast.CallExpr:
+			if function, _ := naming.function(node.Fun); function == "So" && !statements[node] {
+				r.report(node.Pos(), "the result of So is used, so the test was left as a single It")
+				ok = false
+			}
+		}
+		return true
+	})
+	return assertions, ok
+}
+
+/*
+ * So(actual, ShouldEqual, expected) becomes Expect(actual).To(Equal(expected))
+ */
+func (naming goConveyNaming) soAsExpectation(callExpr *ast.CallExpr) (*ast.ExprStmt, string) {
+	if len(callExpr.Args) < 2 {
+		return nil, "it has no assertion"
+	}
+
+	actual, args := callExpr.Args[0], callExpr.Args[2:]
+	assertion, ok := naming.function(callExpr.Args[1])
+	mapping, known := goConveyAssertions[assertion]
+	if !ok || !known {
+		return nil, "the assertion has no gomega equivalent"
+	}
+
+	if assertion == "ShouldAlmostEqual" && len(args) == 1 {
+		// GoConvey's default margin
+		args = append(args, &ast.BasicLit{Kind: token.FLOAT, Value: "0.0000000001"})
+	}
+
+	if len(args) != mapping.args {
+		return nil, fmt.Sprintf("%s takes %d args", assertion, mapping.args)
+	}
+
+	negated, matcher := mapping.build(actual, args)
+	return createExpectation(callExpr.Pos(), actual, negated, matcher, nil), ""
+}
+
+/*
+ * The C passed to a Convey's func can only be used to call Convey, So and Reset
+ */
+func (r *fileRewriter) checkConveyContexts(body *ast.BlockStmt, naming goConveyNaming) bool {
+	allowed := map[*ast.Ident]bool{}
+	ast.Inspect(body, func(node ast.Node) bool {
+		if selectorExpr, ok := node.(*ast.SelectorExpr); ok {
+			ident, ok := selectorExpr.X.(*ast.Ident)
+			if ok && naming.contexts[ident.Name] {
+				_, isConvey := conveyPrefixes[selectorExpr.Sel.Name]
+				allowed[ident] = isConvey || selectorExpr.Sel.Name == "So" || selectorExpr.Sel.Name == "Reset"
+			}
+		}
+		return true
+	})
+
+	ok := true
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.FieldList:
+			return false
+		case *ast.Ident:
+			if ok && naming.contexts[node.Name] && !allowed[node] {
+				r.report(node.Pos(), "'%s' is used for something other than Convey, So and Reset; left the test as a single It", node.Name)
+				ok = false
+			}
+		}
+		return ok
+	})
+	return ok
+}
+
+/*
+ * eg: Convey(...), convey.Convey(...) or c.Convey(...) given func(c C)
+ */
+func (naming goConveyNaming) conveyCall(statement ast.Stmt) (*ast.CallExpr, bool) {
+	exprStmt, ok := statement.(*ast.ExprStmt)
+	if !ok {
+		return nil, false
+	}
+
+	callExpr, ok := exprStmt.X.(*ast.CallExpr)
+	if !ok {
+		return nil, false
+	}
+
+	function, ok := naming.function(callExpr.Fun)
+	_, isConvey := conveyPrefixes[function]
+	return callExpr, ok && isConvey
+}
+
+/*
+ * eg: Reset(func() { stack.Clear() })
+ */
+func (naming goConveyNaming) resetBody(statement ast.Stmt) (*ast.BlockStmt, bool) {
+	exprStmt, ok := statement.(*ast.ExprStmt)
+	if !ok {
+		return nil, false
+	}
+
+	callExpr, ok := exprStmt.X.(*ast.CallExpr)
+	if !ok || len(callExpr.Args) != 1 {
+		return nil, false
+	}
+
+	if function, ok := naming.function(callExpr.Fun); !ok || function != "Reset" {
+		return nil, false
+	}
+
+	funcLit, ok := callExpr.Args[0].(*ast.FuncLit)
+	if !ok {
+		return nil, false
+	}
+	return funcLit.Body, true
+}
+
+/*
+ * The name of one of GoConvey's funcs or assertions, as it is called here
+ */
+func (naming goConveyNaming) function(expr ast.Expr) (string, bool) {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return expr.Name, naming.packageName == "."
+	case *ast.SelectorExpr:
+		ident, ok := expr.X.(*ast.Ident)
+		if !ok {
+			return "", false
+		}
+		return expr.Sel.Name, ident.Name == naming.packageName || naming.contexts[ident.Name]
+	}
+	return "", false
+}
+
+/*
+ * eg: c, given func(c C)
+ */
+func (naming goConveyNaming) contextParam(funcLit *ast.FuncLit) (string, bool) {
+	params := funcLit.Type.Params
+	if params.NumFields() != 1 || len(params.List[0].Names) != 1 {
+		return "", false
+	}
+
+	name, ok := naming.function(params.List[0].Type)
+	return params.List[0].Names[0].Name, ok && name == "C"
+}
@@ -0,0 +1,104 @@
+package converter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+const gomegaImportPath = "github.com/onsi/gomega"
+
+/*
+ * Creates an Expect(actual).To(matcher, description...) node, or
+ * Expect(actual).NotTo(...) when negated. pos is where the assertion
+ * is printed, eg: the if statement it replaces.
+ */
+func createExpectation(pos token.Pos, actual ast.Expr, negated bool, matcher ast.Expr, description []ast.Expr) *ast.ExprStmt {
+	expect := &ast.CallExpr{
+		Fun:  &ast.Ident{NamePos: pos, Name: "Expect"},
+		Args: []ast.Expr{actual},
+	}
+
+	to := "To"
+	if negated {
+		to = "NotTo"
+	}
+
+	callExpr := &ast.CallExpr{
+		Fun:  &ast.SelectorExpr{X: expect, Sel: &ast.Ident{Name: to}},
+		Args: append([]ast.Expr{matcher}, description...),
+	}
+	return &ast.ExprStmt{X: callExpr}
+}
+
+/*
+ * How an assertion from another library maps onto gomega: the number of
+ * args it takes after the actual value, and the matcher they become
+ */
+type matcherMapping struct {
+	args  int
+	build func(actual ast.Expr, args []ast.Expr) (negated bool, matcher ast.Expr)
+}
+
+/*
+ * A mapping onto a matcher that takes the assertion's args as they are,
+ * eg: Equal(expected)
+ */
+func mapsOnto(negated bool, matcher string) func(ast.Expr, []ast.Expr) (bool, ast.Expr) {
+	return func(actual ast.Expr, args []ast.Expr) (bool, ast.Expr) {
+		return negated, createMatcher(matcher, args...)
+	}
+}
+
+/*
+ * A mapping for nil checks, which become HaveOccurred() for errors
+ */
+func mapsOntoNilCheck(negated bool) func(ast.Expr, []ast.Expr) (bool, ast.Expr) {
+	return func(actual ast.Expr, args []ast.Expr) (bool, ast.Expr) {
+		if isErrorName(actual) {
+			return !negated, createMatcher("HaveOccurred")
+		}
+		return negated, createMatcher("BeNil")
+	}
+}
+
+/*
+ * eg: Equal(want), BeNil()
+ */
+func createMatcher(name string, args ...ast.Expr) *ast.CallExpr {
+	return &ast.CallExpr{Fun: &ast.Ident{Name: name}, Args: args}
+}
+
+/*
+ * eg: BeNumerically(">=", want)
+ */
+func createNumericMatcher(comparator string, expected ast.Expr) *ast.CallExpr {
+	comparatorLit := &ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf("%q", comparator)}
+	return createMatcher("BeNumerically", comparatorLit, expected)
+}
+
+/*
+ * The description for an assertion, from the *testing.T call it replaces.
+ * Errorf and Fatalf keep their format string and args. Error and Fatal
+ * format their args like Println, so they get a "%v %v" format of their own.
+ */
+func descriptionForFailure(method string, args []ast.Expr) []ast.Expr {
+	switch method {
+	case "Errorf", "Fatalf":
+		return args
+	case "Error", "Fatal":
+		if len(args) == 0 {
+			return nil
+		}
+
+		if literal, ok := args[0].(*ast.BasicLit); ok && literal.Kind == token.STRING && len(args) == 1 {
+			return args
+		}
+
+		verbs := strings.TrimSpace(strings.Repeat("%v ", len(args)))
+		format := &ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf("%q", verbs)}
+		return append([]ast.Expr{format}, args...)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package converter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+)
+
+/*
+ * Given the root node of an AST, returns its import declarations, eg: both
+ *   import "os"
+ *   import (
+ *     "testing"
+ *   )
+ */
+func importDecls(rootNode *ast.File) (decls []*ast.GenDecl) {
+	for _, declaration := range rootNode.Decls {
+		decl, ok := declaration.(*ast.GenDecl)
+		if ok && decl.Tok == token.IMPORT {
+			decls = append(decls, decl)
+		}
+	}
+	return
+}
+
+/*
+ * Whether the file imports path, under any name
+ */
+func isImported(rootNode *ast.File, path string) bool {
+	quotedPath := fmt.Sprintf("%q", path)
+	for _, decl := range importDecls(rootNode) {
+		for _, spec := range decl.Specs {
+			importSpec, ok := spec.(*ast.ImportSpec)
+			if ok && importSpec.Path.Value == quotedPath {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+/*
+ * Adds a single import statement, if missing. It joins the file's first
+ * import declaration (which gains parentheses if it had a single import),
+ * or a new one after the package clause when the file has none.
+ * An empty name imports the package under its own name.
+ */
+func addImport(rootNode *ast.File, name, path string) {
+	if isImported(rootNode, path) {
+		return
+	}
+
+	importSpec := createImport(name, fmt.Sprintf("%q", path))
+	decls := importDecls(rootNode)
+	if len(decls) == 0 {
+		importDecl := &ast.GenDecl{Tok: token.IMPORT, Specs: []ast.Spec{importSpec}}
+		rootNode.Decls = append([]ast.Decl{importDecl}, rootNode.Decls...)
+	} else {
+		decls[0].Specs = append(decls[0].Specs, importSpec)
+	}
+	rootNode.Imports = append(rootNode.Imports, importSpec)
+}
+
+/*
+ * Adds the import of path, if something in the file refers to name
+ */
+func addImportIfUsed(rootNode *ast.File, name, path string) {
+	if refersToPackage(rootNode, name) {
+		addImport(rootNode, name, path)
+	}
+}
+
+/*
+ * Removes the import of path from whichever declaration holds it. A
+ * declaration left without imports goes away with it.
+ */
+func removeImport(rootNode *ast.File, path string) {
+	quotedPath := fmt.Sprintf("%q", path)
+	isRemoved := func(spec ast.Spec) bool {
+		importSpec, ok := spec.(*ast.ImportSpec)
+		return ok && importSpec.Path.Value == quotedPath
+	}
+
+	decls := []ast.Decl{}
+	for _, decl := range rootNode.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.IMPORT {
+			decls = append(decls, decl)
+			continue
+		}
+
+		specs := []ast.Spec{}
+		for _, spec := range genDecl.Specs {
+			if !isRemoved(spec) {
+				specs = append(specs, spec)
+			}
+		}
+
+		genDecl.Specs = specs
+		if len(specs) > 0 {
+			decls = append(decls, decl)
+		}
+	}
+	rootNode.Decls = decls
+
+	imports := []*ast.ImportSpec{}
+	for _, importSpec := range rootNode.Imports {
+		if !isRemoved(importSpec) {
+			imports = append(imports, importSpec)
+		}
+	}
+	rootNode.Imports = imports
+}
+
+/*
+ * Whether anything in root selects from a package imported as name, eg:
+ * mr.T() for mr
+ */
+func refersToPackage(root ast.Node, name string) bool {
+	used := false
+	ast.Inspect(root, func(node ast.Node) bool {
+		selectorExpr, ok := node.(*ast.SelectorExpr)
+		if !ok {
+			return !used
+		}
+
+		ident, ok := selectorExpr.X.(*ast.Ident)
+		if ok && ident.Name == name {
+			used = true
+		}
+		return !used
+	})
+	return used
+}
+
+/*
+ * Removes the import of path, if nothing in the file refers to name anymore
+ */
+func removeImportIfUnused(rootNode *ast.File, name, path string) {
+	if !refersToPackage(rootNode, name) {
+		removeImport(rootNode, path)
+	}
+}
+
+/*
+ * Removes the dot import of path, if nothing in the file refers to any of
+ * the names it exports anymore
+ */
+func removeDotImportIfUnused(rootNode *ast.File, path string, names []string) {
+	for _, name := range names {
+		if countUnqualifiedIdentifiers(rootNode, name) > 0 {
+			return
+		}
+	}
+	removeImport(rootNode, path)
+}
+
+/*
+ * Removes the import of a package that may be dot imported, given the
+ * names it exports
+ */
+func removeFrameworkImportIfUnused(rootNode *ast.File, name, path string, exported []string) {
+	if name == "." {
+		removeDotImportIfUnused(rootNode, path, exported)
+		return
+	}
+	removeImportIfUnused(rootNode, name, path)
+}
+
+/*
+ * convenience function to create an import statement.
+ * An empty name imports the package under its own name.
+ */
+func createImport(name, path string) *ast.ImportSpec {
+	importSpec := &ast.ImportSpec{Path: &ast.BasicLit{Kind: 9, Value: path}}
+	if name != "" {
+		importSpec.Name = &ast.Ident{Name: name}
+	}
+	return importSpec
+}
@@ -0,0 +1,306 @@
+package converter
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+/*
+ * A comment group leading a node of the original file, eg: a doc comment
+ */
+type leadingComment struct {
+	node  token.Pos
+	group token.Pos
+}
+
+type fileLine struct {
+	file *token.File
+	line int
+}
+
+/*
+ * Records where the comments leading each node start, before the file is
+ * rewritten and its nodes move around
+ */
+func findLeadingComments(fileSet *token.FileSet, rootNode *ast.File) []leadingComment {
+	comments := []leadingComment{}
+	for node, groups := range ast.NewCommentMap(fileSet, rootNode, rootNode.Comments) {
+		for _, group := range groups {
+			if group.End() <= node.Pos() {
+				comments = append(comments, leadingComment{node: node.Pos(), group: group.Pos()})
+			}
+		}
+	}
+	return comments
+}
+
+/*
+ * go/printer lays code out from the positions of its nodes: it keeps the
+ * blank lines there were between two statements, counting from the last
+ * position it printed. The blocks the rewriter creates (eg: an It's body)
+ * have no position, so the printer counts from whatever it printed before,
+ * lines away from their first statement, and opens them with a blank line.
+ * Instead, each of them opens on the line before its first statement (or
+ * before the comments leading it), and closes at the end of the line of the
+ * last position printed for its last statement.
+ */
+func (r *fileRewriter) layOutGeneratedCode() {
+	leading := map[fileLine]token.Pos{}
+	for _, comment := range r.leadingComments {
+		line := lineOf(r.fileSet, comment.node)
+		if start, ok := leading[line]; !ok || comment.group < start {
+			leading[line] = comment.group
+		}
+	}
+
+	// inner blocks first, since where an outer block closes depends on where they do
+	blocks := []*ast.BlockStmt{}
+	ast.Inspect(r.rootNode, func(node ast.Node) bool {
+		if block, ok := node.(*ast.BlockStmt); ok && len(block.List) > 0 {
+			blocks = append([]*ast.BlockStmt{block}, blocks...)
+		}
+		return true
+	})
+
+	for _, block := range blocks {
+		// blocks starting with a container or spec without a position have no blank line to drop
+		start := block.List[0].Pos()
+		if !block.Lbrace.IsValid() && start.IsValid() {
+			if comment, ok := leading[lineOf(r.fileSet, start)]; ok && comment < start {
+				start = comment
+			}
+
+			line := lineOf(r.fileSet, start)
+			if line.line > 1 {
+				block.Lbrace = line.file.LineStart(line.line - 1)
+			}
+		}
+
+		end := lastPrinted(block.List[len(block.List)-1])
+		if !block.Rbrace.IsValid() && end.IsValid() {
+			block.Rbrace = endOfLine(r.fileSet, end-1)
+		}
+
+		for _, statement := range block.List {
+			if !statement.Pos().IsValid() {
+				positionHeader(statement)
+			}
+		}
+	}
+
+	for _, decl := range r.rootNode.Decls {
+		if !decl.Pos().IsValid() {
+			positionHeader(decl)
+		}
+	}
+}
+
+/*
+ * The position of the last character on the line of pos (after any comment
+ * ending the line), so that the next line is kept as it was: a blank line
+ * stays blank, and the next statement follows on the line after
+ */
+func endOfLine(fileSet *token.FileSet, pos token.Pos) token.Pos {
+	line := lineOf(fileSet, pos)
+	if line.line >= line.file.LineCount() {
+		return pos + 1
+	}
+	return line.file.LineStart(line.line+1) - 1
+}
+
+/*
+ * Gives the tokens a generated declaration or statement prints before its
+ * first token with a position (eg: func init() { Describe("Testing with ginkgo", func()
+ * up to the container's {, or BeforeEach(func() up to its {) that token's
+ * position. The printer would guess their positions from the length of what
+ * it printed instead, and print the comments from further down (eg: the doc
+ * comment of the first test, or a comment ending the setup of a hook) in the
+ * middle of them, when the guess goes past where the comments are.
+ * Declarations starting with generated statements (eg: the examples'
+ * helper) are left to be laid out from the length of what they print.
+ */
+func positionHeader(root ast.Node) {
+	header, pos, generated := []ast.Node{}, token.NoPos, false
+	ast.Inspect(root, func(node ast.Node) bool {
+		if pos.IsValid() || generated || node == nil {
+			return false
+		}
+
+		if node.Pos().IsValid() {
+			pos = node.Pos()
+			return false
+		}
+
+		if block, ok := node.(*ast.BlockStmt); ok && len(block.List) > 0 && !firstPosition(block.List[0]).IsValid() {
+			generated = true
+			return false
+		}
+		header = append(header, node)
+		return true
+	})
+
+	if !pos.IsValid() || generated {
+		return
+	}
+
+	for _, node := range header {
+		switch node := node.(type) {
+		case *ast.GenDecl:
+			node.TokPos = pos
+		case *ast.Ident:
+			node.NamePos = pos
+		case *ast.BasicLit:
+			node.ValuePos = pos
+		case *ast.CallExpr:
+			node.Lparen = pos
+		case *ast.FuncType:
+			node.Func = pos
+		case *ast.FieldList:
+			node.Opening = pos
+			if !firstPosition(node).IsValid() {
+				node.Closing = pos
+			}
+		case *ast.BlockStmt:
+			node.Lbrace = pos
+		}
+	}
+}
+
+func lineOf(fileSet *token.FileSet, pos token.Pos) fileLine {
+	file := fileSet.File(pos)
+	if file == nil {
+		return fileLine{}
+	}
+	return fileLine{file: file, line: file.Line(pos)}
+}
+
+/*
+ * The earliest position of the nodes inside root, if any of them has one
+ */
+func firstPosition(root ast.Node) token.Pos {
+	first := token.NoPos
+	ast.Inspect(root, func(node ast.Node) bool {
+		if node != nil && node.Pos().IsValid() && (!first.IsValid() || node.Pos() < first) {
+			first = node.Pos()
+		}
+		return true
+	})
+	return first
+}
+
+/*
+ * Starts a spec built from a func where the func did, eg: It("does something", func() {
+ * where func TestDoesSomething(t *testing.T) { was (or t.Run("does something", ...
+ * for a subtest), so that the func's doc comment prints above the spec and
+ * the blank lines before it are kept. Every token up to the spec's body takes
+ * that position: the printer guesses the position of the tokens without one
+ * from the length of what it printed, and could guess they come after the
+ * comments in the body. A body holding the func's statements closes where
+ * the func's did. Specs holding code from elsewhere (eg: a testify suite's methods)
+ * are left to be laid out with it.
+ */
+func positionSpecAt(spec ast.Stmt, pos token.Pos, funcBody *ast.BlockStmt) {
+	exprStmt, ok := spec.(*ast.ExprStmt)
+	if !ok {
+		return
+	}
+
+	callExpr, ok := exprStmt.X.(*ast.CallExpr)
+	if !ok || firstPosition(callExpr) < pos {
+		return
+	}
+
+	for _, header := range append([]ast.Expr{callExpr.Fun}, callExpr.Args...) {
+		ast.Inspect(header, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.Ident:
+				node.NamePos = positionOr(node.NamePos, pos)
+			case *ast.BasicLit:
+				node.ValuePos = positionOr(node.ValuePos, pos)
+			case *ast.CallExpr:
+				node.Lparen = positionOr(node.Lparen, pos)
+				node.Rparen = positionOr(node.Rparen, pos)
+			case *ast.FuncType:
+				node.Func = positionOr(node.Func, pos)
+			case *ast.FieldList:
+				node.Opening = positionOr(node.Opening, pos)
+				node.Closing = positionOr(node.Closing, pos)
+			case *ast.BlockStmt:
+				return false
+			}
+			return true
+		})
+
+		funcLit, ok := header.(*ast.FuncLit)
+		if !ok {
+			continue
+		}
+
+		// it opens on the line before its first statement, which might not be the func's first one
+		body := funcLit.Body
+		if len(body.List) > 0 && len(funcBody.List) > 0 && body.List[0] == funcBody.List[0] {
+			body.Rbrace = positionOr(body.Rbrace, funcBody.Rbrace)
+		}
+		return
+	}
+}
+
+func positionOr(current, pos token.Pos) token.Pos {
+	if current.IsValid() {
+		return current
+	}
+	return pos
+}
+
+/*
+ * The position just after the last token with a position that the printer
+ * prints for root, eg: the last Entry of a DescribeTable, which prints after
+ * its body even though the table's rows come before the body in the test
+ */
+func lastPrinted(root ast.Node) token.Pos {
+	last := token.NoPos
+	visiting := []ast.Node{}
+	ast.Inspect(root, func(node ast.Node) bool {
+		if node != nil {
+			visiting = append(visiting, node)
+			return true
+		}
+
+		// the printer prints a node's closing token after its children
+		node = visiting[len(visiting)-1]
+		visiting = visiting[:len(visiting)-1]
+		if end := closingEnd(node); end.IsValid() {
+			last = end
+		}
+		return true
+	})
+	return last
+}
+
+/*
+ * Where a node ends, when its last token has a position
+ */
+func closingEnd(node ast.Node) token.Pos {
+	switch node := node.(type) {
+	case *ast.BlockStmt:
+		return endOf(node.Rbrace)
+	case *ast.CallExpr:
+		return endOf(node.Rparen)
+	case *ast.CompositeLit:
+		return endOf(node.Rbrace)
+	case *ast.ParenExpr:
+		return endOf(node.Rparen)
+	case *ast.Ident, *ast.BasicLit:
+		if node.Pos().IsValid() {
+			return node.End()
+		}
+	}
+	return token.NoPos
+}
+
+func endOf(closing token.Pos) token.Pos {
+	if !closing.IsValid() {
+		return token.NoPos
+	}
+	return closing + 1
+}
@@ -0,0 +1,97 @@
+package converter
+
+import (
+	"fmt"
+	"go/ast"
+)
+
+/*
+ * A TestingTBackend describes what *testing.T is replaced with: a func that
+ * returns something that behaves like a T (eg: mr.T()), and the type that
+ * helper funcs should accept instead of a *testing.T (eg: mr.TestingT).
+ * PackageName and ImportPath are empty when the identifiers come from the
+ * dot imported ginkgo package itself.
+ */
+type TestingTBackend struct {
+	PackageName string
+	ImportPath  string
+	TFunc       string
+	TType       string
+}
+
+/*
+ * Replaces *testing.T with github.com/tjarratt/mr_t, the default for ginkgo v1
+ */
+var MrTBackend = TestingTBackend{
+	PackageName: "mr",
+	ImportPath:  "github.com/tjarratt/mr_t",
+	TFunc:       "T",
+	TType:       "TestingT",
+}
+
+/*
+ * Replaces *testing.T with ginkgo's own GinkgoT(), the default for ginkgo v2
+ */
+var GinkgoTBackend = TestingTBackend{
+	TFunc: "GinkgoT",
+	TType: "GinkgoTInterface",
+}
+
+func (backend TestingTBackend) validate() error {
+	if backend.TFunc == "" || backend.TType == "" {
+		return fmt.Errorf("a testing.T backend needs both a func and a type, got func '%s' and type '%s'\n", backend.TFunc, backend.TType)
+	}
+
+	if backend.ImportPath != "" && backend.PackageName == "" {
+		return fmt.Errorf("the testing.T backend '%s' needs a package name\n", backend.ImportPath)
+	}
+	return nil
+}
+
+func (backend TestingTBackend) identifier(name string) ast.Expr {
+	if backend.PackageName == "" {
+		return &ast.Ident{Name: name}
+	}
+
+	return &ast.SelectorExpr{
+		X:   &ast.Ident{Name: backend.PackageName},
+		Sel: &ast.Ident{Name: name},
+	}
+}
+
+/*
+ * eg: mr.T() in place of t, starting where t did
+ */
+func (backend TestingTBackend) newTFromIdent(ident *ast.Ident) *ast.CallExpr {
+	fun := backend.identifier(backend.TFunc)
+	switch fun := fun.(type) {
+	case *ast.Ident:
+		fun.NamePos = ident.NamePos
+	case *ast.SelectorExpr:
+		fun.X.(*ast.Ident).NamePos = ident.NamePos
+	}
+	return &ast.CallExpr{Fun: fun}
+}
+
+func (backend TestingTBackend) newTestingT() ast.Expr {
+	return backend.identifier(backend.TType)
+}
+
+/*
+ * Recognises the calls that newTFromIdent creates, eg: mr.T()
+ */
+func (backend TestingTBackend) isT(expr ast.Expr) bool {
+	callExpr, ok := expr.(*ast.CallExpr)
+	if !ok || len(callExpr.Args) != 0 {
+		return false
+	}
+
+	switch fun := callExpr.Fun.(type) {
+	case *ast.Ident:
+		return backend.PackageName == "" && fun.Name == backend.TFunc
+	case *ast.SelectorExpr:
+		ident, ok := fun.X.(*ast.Ident)
+		return ok && ident.Name == backend.PackageName && fun.Sel.Name == backend.TFunc
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+/*
+ * A goPackage is the subset of `go list -json` output the converter needs.
+ */
+type goPackage struct {
+	Dir          string
+	ImportPath   string
+	Name         string
+	TestGoFiles  []string
+	XTestGoFiles []string
+	Match        []string
+	Module       *goModule
+	Error        *goPackageError
+}
+
+type goModule struct {
+	Path string
+	Dir  string
+	Main bool
+}
+
+type goPackageError struct {
+	Err string
+}
+
+/*
+ * Whether this package was matched by a wildcard pattern (eg: ./...),
+ * in which case `go list` has already found all of its children.
+ */
+func (pkg *goPackage) matchedByWildcard() bool {
+	for _, pattern := range pkg.Match {
+		if strings.Contains(pattern, "...") {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ * Loads packages by shelling out to `go list`, which understands GOPATH,
+ * modules and go.work workspaces alike. patterns may be import paths
+ * (my-module/tools), relative or absolute directories (./internal/...),
+ * or any other pattern `go list` accepts.
+ */
+func loadPackages(patterns ...string) ([]*goPackage, error) {
+	args := append([]string{"list", "-e", "-json"}, patterns...)
+	cmd := exec.Command("go", args...)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("error running 'go list %s':\n%s\n", strings.Join(patterns, " "), stderr.String())
+	}
+
+	packages := []*goPackage{}
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	for {
+		pkg := &goPackage{}
+		err = decoder.Decode(pkg)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading output of 'go list %s':\n%s\n", strings.Join(patterns, " "), err.Error())
+		}
+
+		if pkg.Error != nil {
+			return nil, packageLoadingError(pkg)
+		}
+		packages = append(packages, pkg)
+	}
+
+	if len(packages) == 0 {
+		return nil, fmt.Errorf("no packages matched '%s'\n%s", strings.Join(patterns, " "), stderr.String())
+	}
+	return packages, nil
+}
+
+/*
+ * Loads the package in the given directory, or with the given import path
+ */
+func loadPackage(pattern string) (*goPackage, error) {
+	packages, err := loadPackages(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(packages) != 1 {
+		return nil, fmt.Errorf("expected '%s' to match exactly one package, but it matched %d\n", pattern, len(packages))
+	}
+	return packages[0], nil
+}
+
+/*
+ * Turns the error `go list` reported for a package into something a user
+ * can act on. The most common confusing case is a directory that is not
+ * part of the main module (or of any module in go.work).
+ */
+func packageLoadingError(pkg *goPackage) error {
+	location := pkg.ImportPath
+	if pkg.Dir != "" {
+		location = pkg.Dir
+	}
+
+	message := pkg.Error.Err
+	if strings.Contains(message, "outside main module") || strings.Contains(message, "outside module root") ||
+		strings.Contains(message, "main module does not contain") {
+		return fmt.Errorf("'%s' is outside the main module.\n"+
+			"Run ginkgo-converter from inside the module that contains it, or add that module to your go.work file.\n%s\n", location, message)
+	}
+
+	return fmt.Errorf("unexpected error reading package: '%s'\n%s\n", location, message)
+}
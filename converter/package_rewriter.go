@@ -0,0 +1,64 @@
+package converter
+
+import (
+	"bytes"
+)
+
+/*
+ * ConvertPackage converts a single package (and its children). See ConvertPackages.
+ */
+func (c *Converter) ConvertPackage(packageName string) (changes []FileChange, diagnostics []Diagnostic, err error) {
+	return c.ConvertPackages(packageName)
+}
+
+/*
+ * ConvertPackages takes one or more names or patterns (eg: my-package/tools
+ * or ./internal/...), finds their test files using `go list`, and then
+ * rewrites them in memory. A ginkgo test suite file will also be added for
+ * each package, and all of its child packages, unless the converter was
+ * created with SkipSuiteFiles.
+ * Files with no tests left to convert have no change.
+ * The returned changes have not been written to disk yet.
+ */
+func (c *Converter) ConvertPackages(patterns ...string) (changes []FileChange, diagnostics []Diagnostic, err error) {
+	_, packages, diagnostics, err := c.findTestsForPatterns(patterns)
+	if err != nil {
+		return nil, diagnostics, err
+	}
+
+	for _, pkg := range packages {
+		data, err := templateDataForPackage(pkg)
+		if err != nil {
+			return nil, diagnostics, err
+		}
+
+		for _, filename := range testFilesInPackage(pkg) {
+			change, fileDiagnostics, err := c.rewriteTestsInFile(filename, data)
+			diagnostics = append(diagnostics, fileDiagnostics...)
+			if err != nil {
+				return nil, diagnostics, err
+			}
+
+			// files without tests left to convert (eg: converted ones) are not rewritten
+			if bytes.Equal(change.Converted, change.Original) {
+				continue
+			}
+			changes = append(changes, change)
+		}
+	}
+
+	if c.options.SkipSuiteFiles {
+		return changes, diagnostics, nil
+	}
+
+	for _, pkg := range packages {
+		suite, ok, err := c.addGinkgoSuiteForPackage(pkg)
+		if err != nil {
+			return nil, diagnostics, err
+		}
+		if ok {
+			changes = append(changes, suite)
+		}
+	}
+	return changes, diagnostics, nil
+}
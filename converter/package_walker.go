@@ -0,0 +1,176 @@
+package converter
+
+import (
+	"fmt"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+/*
+ * A packageWalker collects the test files of every package below a set of
+ * root packages, following the same conventions as the go tool does for ./...
+ */
+type packageWalker struct {
+	visited     map[string]bool
+	testfiles   []string
+	packages    []*goPackage
+	diagnostics []Diagnostic
+}
+
+/*
+ * Loads every package matching the patterns, and finds the test files in each
+ * of them. Packages named without a wildcard also have their child packages
+ * searched, unless the converter is NonRecursive. Packages are only visited
+ * once, even when several patterns match them.
+ * Directories that could not be loaded as packages are returned as diagnostics.
+ */
+func (c *Converter) findTestsForPatterns(patterns []string) (testfiles []string, packages []*goPackage, diagnostics []Diagnostic, err error) {
+	roots, err := loadPackages(patterns...)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	walker := &packageWalker{visited: map[string]bool{}}
+	for _, pkg := range roots {
+		if c.options.NonRecursive || pkg.matchedByWildcard() {
+			walker.addPackage(pkg)
+			continue
+		}
+
+		err = walker.walkPackage(pkg)
+		if err != nil {
+			return nil, nil, walker.diagnostics, err
+		}
+	}
+	return walker.testfiles, walker.packages, walker.diagnostics, nil
+}
+
+/*
+ * Records the test files for a package, returning false if the package's
+ * directory was already visited (eg: through a symlink).
+ */
+func (w *packageWalker) addPackage(pkg *goPackage) bool {
+	if !w.visit(pkg.Dir) {
+		return false
+	}
+
+	w.testfiles = append(w.testfiles, testFilesInPackage(pkg)...)
+	w.packages = append(w.packages, pkg)
+	return true
+}
+
+/*
+ * Marks a directory as visited. Symlinks are resolved first, so that
+ * following a symlink can never walk the same directory twice (or forever).
+ */
+func (w *packageWalker) visit(dir string) bool {
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		realDir = dir
+	}
+
+	if w.visited[realDir] {
+		return false
+	}
+	w.visited[realDir] = true
+	return true
+}
+
+/*
+ * Given a package, walkPackage reads the test files in the directory,
+ * and then recurses on each child directory.
+ */
+func (w *packageWalker) walkPackage(pkg *goPackage) error {
+	if !w.addPackage(pkg) {
+		return nil
+	}
+
+	return w.walkChildren(pkg.Dir)
+}
+
+/*
+ * Walks the directories inside dir. Child packages are loaded by their
+ * directory rather than by import path, so this works the same way for
+ * GOPATH and module based packages. Like the go tool, we skip vendor and
+ * testdata directories, and anything starting with "." or "_".
+ * Directories that are not go packages are noted, and their children
+ * are still searched.
+ */
+func (w *packageWalker) walkChildren(dir string) error {
+	dirFiles, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("unexpected error reading dir: '%s'\n%s\n", dir, err.Error())
+	}
+
+	for _, file := range dirFiles {
+		childDir := filepath.Join(dir, file.Name())
+		if !isDir(file, childDir) || shouldSkipDir(file.Name()) {
+			continue
+		}
+
+		if !containsGoFiles(childDir) {
+			w.warn(childDir, "skipping '%s': no Go files", childDir)
+			if !w.visit(childDir) {
+				continue
+			}
+
+			err = w.walkChildren(childDir)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		subPackage, err := loadPackage(childDir)
+		if err != nil {
+			w.warn(childDir, "skipping '%s': %s", childDir, strings.TrimSpace(err.Error()))
+			continue
+		}
+
+		err = w.walkPackage(subPackage)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *packageWalker) warn(dir string, format string, args ...interface{}) {
+	w.diagnostics = append(w.diagnostics, Diagnostic{
+		Pos:     token.Position{Filename: dir},
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+/*
+ * Whether a directory entry is a directory, or a symlink to one
+ */
+func isDir(file os.FileInfo, path string) bool {
+	if file.Mode()&os.ModeSymlink == 0 {
+		return file.IsDir()
+	}
+
+	target, err := os.Stat(path)
+	return err == nil && target.IsDir()
+}
+
+func shouldSkipDir(name string) bool {
+	return name == "vendor" || name == "testdata" ||
+		strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")
+}
+
+func containsGoFiles(dir string) bool {
+	matches, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	return err == nil && len(matches) > 0
+}
+
+func testFilesInPackage(pkg *goPackage) (testfiles []string) {
+	for _, file := range append(pkg.TestGoFiles, pkg.XTestGoFiles...) {
+		testfiles = append(testfiles, filepath.Join(pkg.Dir, file))
+	}
+	return
+}
@@ -0,0 +1,412 @@
+package converter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+)
+
+/*
+ * A subtest is a t.Run("name", func(t *testing.T) { ... }) call
+ */
+type subtest struct {
+	pos      token.Pos
+	name     *ast.BasicLit
+	body     *ast.BlockStmt
+	testingT string
+}
+
+/*
+ * Creates the spec for a test func. A test running a testify suite becomes
+ * a Describe holding the suite, Convey blocks become containers and specs
+ * of their own (when converting GoConvey), a table driven test becomes a
+ * DescribeTable, a test whose body is only subtests (after some optional
+ * setup) becomes a Describe with one It per subtest, and anything else
+ * becomes a single It. Also returns the name of the test's *testing.T as
+ * it is used in the spec, which is empty when the spec does not use it.
+ * The spec is nil when the test must be left as it is.
+ */
+func (r *fileRewriter) createSpecForTestFunc(testFunc *ast.FuncDecl) (ast.Stmt, string) {
+	name := &ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf("%q", rewriteTestName(testFunc.Name.Name))}
+	testingT := namedTestingTArg(testFunc)
+
+	testSuite, runsSuite := r.createDescribeForTestifySuite(testFunc, name)
+	if runsSuite && testSuite == nil {
+		return nil, testingT
+	} else if runsSuite {
+		return testSuite, ""
+	}
+
+	convey, ok := r.createSpecForGoConvey(testFunc, name)
+	if ok {
+		return convey, testingT
+	}
+
+	table, ok := r.createTableForBody(name, testFunc.Body, testingT)
+	if ok {
+		return table, testingT
+	}
+
+	setup, subtests, ok := r.splitSubtests(testFunc.Body, testingT)
+	if !ok {
+		return createItStatementForTestFunc(testFunc), testingT
+	}
+
+	describe, ok := r.createContainerForSubtests("Describe", name, setup, subtests)
+	if !ok {
+		return createItStatementForTestFunc(testFunc), testingT
+	}
+	return describe, testingT
+}
+
+/*
+ * Converts a subtest to a DescribeTable when it is table driven, to a
+ * Context when it has subtests of its own, and otherwise to an It
+ */
+func (r *fileRewriter) createSpecForSubtest(test subtest) ast.Stmt {
+	if r.target.hasCleanupAndHelper {
+		replaceCleanupAndHelperCalls(test.body, test.testingT)
+	}
+
+	spec, ok := r.createTableForBody(test.name, test.body, test.testingT)
+	if ok {
+		return spec
+	}
+
+	setup, subtests, ok := r.splitSubtests(test.body, test.testingT)
+	if ok {
+		spec, ok = r.createContainerForSubtests("Context", test.name, setup, subtests)
+	}
+	if !ok {
+		spec = createGinkgoStatement("It", test.name, test.body.List)
+	}
+
+	positionSpecAt(spec, test.pos, test.body)
+	block, _ := blockStatementFromDescribe(spec)
+	replaceTestingTsWithMrT(block, test.testingT, r.target.backend)
+	return spec
+}
+
+/*
+ * Creates a Describe or Context with a BeforeEach for the setup, followed
+ * by the specs for each subtest. Variables declared by the setup are
+ * declared in the container so that the specs can still refer to them.
+ */
+func (r *fileRewriter) createContainerForSubtests(kind string, name *ast.BasicLit, setup []ast.Stmt, subtests []subtest) (*ast.ExprStmt, bool) {
+	statements, ok := r.createSetupNodes(setup)
+	if !ok {
+		return nil, false
+	}
+
+	for _, test := range subtests {
+		statements = append(statements, r.createSpecForSubtest(test))
+	}
+	return createGinkgoStatement(kind, name, statements), true
+}
+
+/*
+ * Splits a test's statements into the setup that precedes its subtests,
+ * and the subtests themselves. ok is false when the test has no subtests,
+ * or has some that cannot be turned into specs (reporting why).
+ */
+func (r *fileRewriter) splitSubtests(body *ast.BlockStmt, testingT string) (setup []ast.Stmt, subtests []subtest, ok bool) {
+	if body == nil || testingT == "" {
+		return nil, nil, false
+	}
+
+	first := -1
+	for index, statement := range body.List {
+		if isTestingTRunCall(statement, testingT) {
+			first = index
+			break
+		}
+	}
+
+	if first < 0 {
+		return nil, nil, false
+	}
+
+	for _, statement := range body.List[first:] {
+		test, ok := subtestInStatement(statement, testingT)
+		if !ok {
+			r.report(statement.Pos(), "subtests must be t.Run calls with a literal name and a func literal, and the last statements of a test; left the test as a single It")
+			return nil, nil, false
+		}
+		subtests = append(subtests, test)
+	}
+
+	return body.List[:first], subtests, true
+}
+
+/*
+ * eg: t.Run(...)
+ */
+func isTestingTRunCall(statement ast.Stmt, testingT string) bool {
+	exprStmt, ok := statement.(*ast.ExprStmt)
+	if !ok {
+		return false
+	}
+
+	callExpr, ok := exprStmt.X.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+
+	selectorExpr, ok := callExpr.Fun.(*ast.SelectorExpr)
+	if !ok || selectorExpr.Sel.Name != "Run" {
+		return false
+	}
+
+	ident, ok := selectorExpr.X.(*ast.Ident)
+	return ok && ident.Name == testingT
+}
+
+/*
+ * eg: t.Run("does something neat", func(t *testing.T) { ... })
+ */
+func subtestInStatement(statement ast.Stmt, testingT string) (test subtest, ok bool) {
+	if !isTestingTRunCall(statement, testingT) {
+		return test, false
+	}
+
+	callExpr := statement.(*ast.ExprStmt).X.(*ast.CallExpr)
+	if len(callExpr.Args) != 2 {
+		return test, false
+	}
+
+	name, ok := callExpr.Args[0].(*ast.BasicLit)
+	if !ok || name.Kind != token.STRING {
+		return test, false
+	}
+
+	funcLit, ok := callExpr.Args[1].(*ast.FuncLit)
+	if !ok || len(funcLit.Type.Params.List) != 1 {
+		return test, false
+	}
+
+	param := funcLit.Type.Params.List[0]
+	if !isTestingTPointer(param.Type) {
+		return test, false
+	}
+
+	test = subtest{pos: statement.Pos(), name: name, body: funcLit.Body}
+	if len(param.Names) > 0 {
+		test.testingT = param.Names[0].Name
+	}
+	return test, true
+}
+
+/*
+ * Creates the nodes running a container's setup before each of its specs:
+ * the declarations of the variables the setup declares, and a BeforeEach
+ * making the rest of it. Calls the setup defers are made after each spec,
+ * by DeferCleanup with ginkgo v2 and by an AfterEach with v1, eg:
+ *   defer db.Close()
+ * becomes AfterEach(func() { db.Close() }). ok is false when the setup
+ * cannot be moved (reporting why).
+ */
+func (r *fileRewriter) createSetupNodes(setup []ast.Stmt) (statements []ast.Stmt, ok bool) {
+	if len(setup) == 0 {
+		return nil, true
+	}
+
+	beforeEach, deferred := []ast.Stmt{}, []ast.Stmt{}
+	for _, statement := range setup {
+		deferStmt, isDefer := statement.(*ast.DeferStmt)
+		switch {
+		case isDefer && r.target.hasCleanupAndHelper:
+			beforeEach = append(beforeEach, deferCleanupFor(deferStmt))
+		case isDefer:
+			// deferred calls are made in the reverse order
+			deferred = append([]ast.Stmt{&ast.ExprStmt{X: deferStmt.Call}}, deferred...)
+		case defersInsideBlock(statement):
+			r.report(statement.Pos(), "could not move a call deferred inside a block into a BeforeEach; left the test as a single It")
+			return nil, false
+		default:
+			beforeEach = append(beforeEach, statement)
+		}
+	}
+
+	declarations, assignments, ok := r.hoistDeclarations(beforeEach, hoistingIntoBeforeEach)
+	if !ok {
+		return nil, false
+	}
+
+	statements = declarations
+	if len(assignments) > 0 {
+		statements = append(statements, createGinkgoStatement("BeforeEach", nil, assignments))
+	}
+	if len(deferred) > 0 {
+		statements = append(statements, createGinkgoStatement("AfterEach", nil, deferred))
+	}
+	return statements, true
+}
+
+/*
+ * Whether a statement defers a call from inside one of its blocks, eg:
+ *   if cache { defer cleanUp() }
+ */
+func defersInsideBlock(statement ast.Stmt) bool {
+	defers := false
+	ast.Inspect(statement, func(node ast.Node) bool {
+		switch node.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.DeferStmt:
+			defers = true
+		}
+		return !defers
+	})
+	return defers
+}
+
+const hoistingIntoBeforeEach = "to move it into a BeforeEach; left the test as a single It"
+
+/*
+ * Moves the declarations in a BeforeEach's statements out into var
+ * declarations, leaving assignments behind, eg:
+ *   app := &App{}
+ * becomes `var app *App` in the container and `app = &App{}` in the
+ * BeforeEach. Constants and types move out as they are. ok is false when a type cannot be inferred, in which case the
+ * statements are left untouched and the report explains what happened
+ * instead, eg: "to move it into a BeforeEach; left the test as a single It"
+ */
+func (r *fileRewriter) hoistDeclarations(statements []ast.Stmt, instead string) (declarations, assignments []ast.Stmt, ok bool) {
+	for _, statement := range statements {
+		switch statement := statement.(type) {
+		case *ast.AssignStmt:
+			if statement.Tok != token.DEFINE {
+				break
+			}
+
+			if len(statement.Lhs) != len(statement.Rhs) {
+				r.report(statement.Pos(), "could not infer the types declared by this statement %s", instead)
+				return nil, nil, false
+			}
+
+			for index, lhs := range statement.Lhs {
+				ident, isIdent := lhs.(*ast.Ident)
+				if !isIdent || ident.Name == "_" {
+					continue
+				}
+
+				valueType, ok := typeOfExpression(statement.Rhs[index])
+				if !ok {
+					r.report(statement.Pos(), "could not infer the type of '%s' %s", ident.Name, instead)
+					return nil, nil, false
+				}
+				declarations = append(declarations, createVarDeclaration(ident.Name, valueType))
+			}
+
+			assignment := *statement
+			assignment.Tok = token.ASSIGN
+			assignments = append(assignments, &assignment)
+			continue
+
+		case *ast.DeclStmt:
+			genDecl, isGenDecl := statement.Decl.(*ast.GenDecl)
+			if isGenDecl && (genDecl.Tok == token.CONST || genDecl.Tok == token.TYPE) {
+				// declared alongside the variables, where the specs can refer to them too
+				declarations = append(declarations, statement)
+				continue
+			}
+
+			if !isGenDecl || genDecl.Tok != token.VAR {
+				break
+			}
+
+			assignment := &ast.AssignStmt{Tok: token.ASSIGN, TokPos: statement.Pos()}
+			for _, spec := range genDecl.Specs {
+				valueSpec := spec.(*ast.ValueSpec)
+				for index, name := range valueSpec.Names {
+					valueType := valueSpec.Type
+					if valueType == nil && len(valueSpec.Values) == len(valueSpec.Names) {
+						valueType, _ = typeOfExpression(valueSpec.Values[index])
+					}
+
+					if valueType == nil {
+						r.report(statement.Pos(), "could not infer the type of '%s' %s", name.Name, instead)
+						return nil, nil, false
+					}
+					declarations = append(declarations, createVarDeclaration(name.Name, valueType))
+
+					if len(valueSpec.Values) > 0 {
+						assignment.Lhs = append(assignment.Lhs, name)
+					}
+				}
+				assignment.Rhs = append(assignment.Rhs, valueSpec.Values...)
+			}
+
+			if len(assignment.Lhs) > 0 {
+				assignments = append(assignments, assignment)
+			}
+			continue
+		}
+
+		assignments = append(assignments, statement)
+	}
+	return declarations, assignments, true
+}
+
+/*
+ * The type of an expression, for the few kinds of expression where it
+ * can be known without type checking the package
+ */
+func typeOfExpression(expr ast.Expr) (ast.Expr, bool) {
+	switch expr := expr.(type) {
+	case *ast.ParenExpr:
+		return typeOfExpression(expr.X)
+	case *ast.CompositeLit:
+		return expr.Type, expr.Type != nil
+	case *ast.UnaryExpr:
+		if expr.Op != token.AND {
+			return nil, false
+		}
+
+		valueType, ok := typeOfExpression(expr.X)
+		if !ok {
+			return nil, false
+		}
+		return &ast.StarExpr{X: valueType}, true
+	case *ast.FuncLit:
+		return expr.Type, true
+	case *ast.BasicLit:
+		switch expr.Kind {
+		case token.INT:
+			return &ast.Ident{Name: "int"}, true
+		case token.FLOAT:
+			return &ast.Ident{Name: "float64"}, true
+		case token.IMAG:
+			return &ast.Ident{Name: "complex128"}, true
+		case token.CHAR:
+			return &ast.Ident{Name: "rune"}, true
+		case token.STRING:
+			return &ast.Ident{Name: "string"}, true
+		}
+	case *ast.Ident:
+		if expr.Name == "true" || expr.Name == "false" {
+			return &ast.Ident{Name: "bool"}, true
+		}
+	case *ast.CallExpr:
+		fun, ok := expr.Fun.(*ast.Ident)
+		if !ok || len(expr.Args) == 0 {
+			return nil, false
+		}
+
+		switch fun.Name {
+		case "new":
+			return &ast.StarExpr{X: expr.Args[0]}, true
+		case "make":
+			return expr.Args[0], true
+		}
+	}
+	return nil, false
+}
+
+/*
+ * eg: var app *App
+ */
+func createVarDeclaration(name string, valueType ast.Expr) *ast.DeclStmt {
+	valueSpec := &ast.ValueSpec{Names: []*ast.Ident{{Name: name}}, Type: valueType}
+	return &ast.DeclStmt{Decl: &ast.GenDecl{Tok: token.VAR, Specs: []ast.Spec{valueSpec}}}
+}
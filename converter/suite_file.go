@@ -0,0 +1,109 @@
+package converter
+
+import (
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+/*
+ * The same suite file `ginkgo bootstrap` would create
+ */
+const defaultSuiteTemplate = `package {{.PackageName}}
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func Test{{.FormattedName}}(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "{{.SuiteTitle}}")
+}
+`
+
+/*
+ * The same suite file `ginkgo bootstrap` creates for ginkgo v2
+ */
+const defaultV2SuiteTemplate = `package {{.PackageName}}
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func Test{{.FormattedName}}(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "{{.SuiteTitle}}")
+}
+`
+
+/*
+ * Creates a <pkg>_suite_test.go file for the package, unless it already has one.
+ * The suite lives in the same package as the existing tests: package foo_test
+ * if any of them are external tests, otherwise package foo.
+ */
+func (c *Converter) addGinkgoSuiteForPackage(pkg *goPackage) (change FileChange, ok bool, err error) {
+	data, err := templateDataForPackage(pkg)
+	if err != nil {
+		return change, false, err
+	}
+
+	suite_test_file := filepath.Join(pkg.Dir, data.PackageName+"_suite_test.go")
+	_, err = os.Stat(suite_test_file)
+	if err == nil {
+		return change, false, nil // test file already exists, this should be a no-op
+	}
+
+	if len(pkg.XTestGoFiles) > 0 {
+		data.PackageName += "_test"
+	}
+
+	target, err := c.target()
+	if err != nil {
+		return change, false, err
+	}
+
+	suiteTemplate := c.options.SuiteTemplate
+	if suiteTemplate == "" {
+		suiteTemplate = target.suiteTemplate
+	}
+
+	rendered, err := renderTemplate("suite", suiteTemplate, data)
+	if err != nil {
+		return change, false, err
+	}
+
+	contents, err := format.Source(rendered)
+	if err != nil {
+		return change, false, fmt.Errorf("error formatting suite file for '%s':\n%s\n", data.ImportPath, err.Error())
+	}
+
+	change = FileChange{
+		Path:      suite_test_file,
+		Converted: contents,
+		Mode:      0644,
+	}
+	return change, true, nil
+}
+
+/*
+ * Turns a directory name like "my-great_package" into "MyGreatPackage",
+ * the same way `ginkgo bootstrap` names its suites.
+ */
+func prettifyPackageName(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return r == '-' || r == '_' || r == ' ' || r == '.'
+	})
+
+	for index, word := range words {
+		words[index] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, "")
+}
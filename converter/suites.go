@@ -0,0 +1,442 @@
+package converter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+/*
+ * xunit style suite frameworks (testify's suite package, gocheck) run the
+ * methods of a struct as tests, with fixture methods around them. A
+ * suiteStyle describes one of them.
+ */
+type suiteStyle struct {
+	// the fixture methods, and the ginkgo nodes they become
+	hooks map[string]string
+
+	// whether a test or fixture method takes the params the framework
+	// passes to it, eg: none for testify, (c *C) for gocheck
+	params func(*ast.FieldList) bool
+
+	// the methods the framework calls that have no ginkgo equivalent
+	unsupported func(name string) bool
+}
+
+/*
+ * The hooks that run once for a whole suite, which need an Ordered
+ * container. ginkgo v1 has neither, and its BeforeSuite and AfterSuite are
+ * allowed once per test suite (ie: package), so they cannot stand in.
+ */
+var suiteLevelHooks = map[string]bool{
+	"BeforeAll": true,
+	"AfterAll":  true,
+}
+
+/*
+ * A suite's struct, declared in this file along with its methods
+ */
+type xunitSuite struct {
+	style    suiteStyle
+	typeDecl *ast.GenDecl
+	typeSpec *ast.TypeSpec
+	fields   []tableField
+	embedded []ast.Expr
+	values   map[string]ast.Expr
+	methods  []*ast.FuncDecl
+
+	// names that refer to the suite's fields and helper methods once they
+	// are variables of the Describe, eg: s.db becomes db
+	members map[string]bool
+}
+
+func (testSuite xunitSuite) isTest(method *ast.FuncDecl) bool {
+	return strings.HasPrefix(method.Name.Name, "Test")
+}
+
+func (testSuite xunitSuite) isHook(method *ast.FuncDecl) bool {
+	_, ok := testSuite.style.hooks[method.Name.Name]
+	return ok
+}
+
+/*
+ * Finds the struct named typeName and its methods. Suites are usually
+ * declared in the file that runs them; one that is not (or whose tests
+ * are not) cannot be converted here.
+ */
+func (r *fileRewriter) findSuite(typeName string, style suiteStyle) (testSuite xunitSuite, reason string) {
+	testSuite.style = style
+	for _, decl := range r.rootNode.Decls {
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			if decl.Tok != token.TYPE {
+				continue
+			}
+
+			for _, spec := range decl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				if typeSpec.Name.Name == typeName {
+					testSuite.typeDecl, testSuite.typeSpec = decl, typeSpec
+				}
+			}
+		case *ast.FuncDecl:
+			if receiver, ok := receiverTypeName(decl); ok && receiver == typeName {
+				testSuite.methods = append(testSuite.methods, decl)
+			}
+		}
+	}
+
+	if testSuite.typeSpec == nil {
+		return testSuite, fmt.Sprintf("%s is not declared in this file", typeName)
+	}
+
+	structType, ok := testSuite.typeSpec.Type.(*ast.StructType)
+	if !ok {
+		return testSuite, fmt.Sprintf("%s is not a struct", typeName)
+	}
+
+	testSuite.members = map[string]bool{}
+	for _, field := range structType.Fields.List {
+		if len(field.Names) == 0 {
+			testSuite.embedded = append(testSuite.embedded, field.Type)
+			continue
+		}
+
+		for _, name := range field.Names {
+			testSuite.fields = append(testSuite.fields, tableField{name: name.Name, fieldType: field.Type})
+			testSuite.members[name.Name] = true
+		}
+	}
+
+	hasTests := false
+	for _, method := range testSuite.methods {
+		methodName := method.Name.Name
+		switch {
+		case style.unsupported(methodName):
+			return testSuite, fmt.Sprintf("%s.%s has no ginkgo equivalent", typeName, methodName)
+		case method.Body == nil:
+			return testSuite, fmt.Sprintf("%s.%s has no body", typeName, methodName)
+		case testSuite.isTest(method) || testSuite.isHook(method):
+			if !style.params(method.Type.Params) || method.Type.Results.NumFields() > 0 {
+				return testSuite, fmt.Sprintf("%s.%s does not have the signature of a test or fixture", typeName, methodName)
+			}
+
+			if suiteLevelHooks[style.hooks[methodName]] && r.target.useInitFunc {
+				return testSuite, fmt.Sprintf("%s.%s runs once for the suite, which ginkgo v1 cannot do (convert with --ginkgo-version v2)", typeName, methodName)
+			}
+			hasTests = hasTests || testSuite.isTest(method)
+		default:
+			testSuite.members[methodName] = true
+		}
+	}
+
+	if !hasTests {
+		return testSuite, fmt.Sprintf("the tests of %s are not declared in this file", typeName)
+	}
+	return testSuite, ""
+}
+
+/*
+ * The type of suite that is run, and the values its fields start with,
+ * eg: new(DatabaseSuite) or &DatabaseSuite{driver: "sqlite"}
+ */
+func suiteValue(value ast.Expr) (typeName string, values map[string]ast.Expr, reason string) {
+	if args, ok := identCallArgs(value, "new"); ok && len(args) == 1 {
+		if ident, ok := args[0].(*ast.Ident); ok {
+			return ident.Name, nil, ""
+		}
+	}
+
+	unaryExpr, ok := value.(*ast.UnaryExpr)
+	if !ok || unaryExpr.Op != token.AND {
+		return "", nil, "the suite must be created where it is run, eg: new(Suite)"
+	}
+
+	literal, ok := unaryExpr.X.(*ast.CompositeLit)
+	if !ok {
+		return "", nil, "the suite must be created where it is run, eg: new(Suite)"
+	}
+
+	ident, ok := literal.Type.(*ast.Ident)
+	if !ok || !isKeyedLiteral(literal) {
+		return "", nil, "the suite must be a named type, created with a keyed literal"
+	}
+
+	values = map[string]ast.Expr{}
+	for _, element := range literal.Elts {
+		keyValue := element.(*ast.KeyValueExpr)
+		key, ok := keyValue.Key.(*ast.Ident)
+		if !ok {
+			return "", nil, "the suite must be a named type, created with a keyed literal"
+		}
+		values[key.Name] = keyValue.Value
+	}
+	return ident.Name, values, ""
+}
+
+/*
+ * eg: DatabaseSuite, for func (s *DatabaseSuite) TestInsert()
+ */
+func receiverTypeName(decl *ast.FuncDecl) (string, bool) {
+	if decl.Recv == nil || len(decl.Recv.List) != 1 {
+		return "", false
+	}
+
+	receiverType := decl.Recv.List[0].Type
+	if starExpr, ok := receiverType.(*ast.StarExpr); ok {
+		receiverType = starExpr.X
+	}
+
+	ident, ok := receiverType.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	return ident.Name, true
+}
+
+/*
+ * A method of a suite, with the statements that change once it is converted
+ */
+type suiteMethod struct {
+	decl     *ast.FuncDecl
+	receiver string
+
+	// assertions to replace with gomega, or statements to remove when
+	// they map to nil
+	replacements map[ast.Stmt]ast.Stmt
+
+	// the uses of the receiver (and the framework's other values, eg: c *C)
+	// that are known to convert
+	converted map[*ast.Ident]bool
+}
+
+/*
+ * Starts checking a method of the suite: once it is converted, the names
+ * of the suite's fields and helpers must not refer to anything else in it.
+ * Uses of the receiver that select one of them (eg: s.db) are converted.
+ */
+func newSuiteMethod(testSuite xunitSuite, decl *ast.FuncDecl) (method suiteMethod, reason string) {
+	method = suiteMethod{
+		decl:         decl,
+		replacements: map[ast.Stmt]ast.Stmt{},
+		converted:    map[*ast.Ident]bool{},
+	}
+
+	if names := decl.Recv.List[0].Names; len(names) == 1 && names[0].Name != "_" {
+		method.receiver = names[0].Name
+	}
+
+	for member := range testSuite.members {
+		if countUnqualifiedIdentifiers(decl.Body, member) > 0 {
+			return method, fmt.Sprintf("'%s' would refer to the suite's %s once it is converted", member, member)
+		}
+	}
+
+	ast.Inspect(decl.Body, func(node ast.Node) bool {
+		if selectorExpr, ok := node.(*ast.SelectorExpr); ok && testSuite.members[selectorExpr.Sel.Name] {
+			if ident, ok := selectorExpr.X.(*ast.Ident); ok && ident.Name == method.receiver {
+				method.converted[ident] = true
+			}
+		}
+		return true
+	})
+	return method, ""
+}
+
+/*
+ * Returns why the method cannot be converted when it uses one of names in
+ * a way that was not converted, eg: s.Run or passing s to another func
+ */
+func (method suiteMethod) unconvertedUse(names map[string]bool) (reason string) {
+	ast.Inspect(method.decl.Body, func(node ast.Node) bool {
+		if reason != "" {
+			return false
+		}
+
+		switch node := node.(type) {
+		case *ast.SelectorExpr:
+			if ident, ok := node.X.(*ast.Ident); ok && names[ident.Name] && !method.converted[ident] {
+				reason = fmt.Sprintf("%s.%s is not supported", ident.Name, node.Sel.Name)
+			}
+		case *ast.Ident:
+			if names[node.Name] && !method.converted[node] {
+				reason = fmt.Sprintf("'%s' is used as a value", node.Name)
+			}
+		}
+		return true
+	})
+	return reason
+}
+
+/*
+ * Replaces a checked method's statements, and the receiver's fields and
+ * helpers with the Describe's variables, eg: s.db becomes db
+ */
+func (r *fileRewriter) rewriteSuiteMethod(testSuite xunitSuite, method suiteMethod) {
+	if replaceStatements(method.decl.Body, method.replacements) {
+		r.usesGomega = true
+	}
+
+	if method.receiver == "" {
+		return
+	}
+
+	replaceExpressions(method.decl.Body, func(expr ast.Expr) ast.Expr {
+		selectorExpr, ok := expr.(*ast.SelectorExpr)
+		if !ok || !testSuite.members[selectorExpr.Sel.Name] {
+			return expr
+		}
+
+		if ident, ok := selectorExpr.X.(*ast.Ident); ok && ident.Name == method.receiver {
+			return &ast.Ident{NamePos: ident.NamePos, Name: selectorExpr.Sel.Name}
+		}
+		return expr
+	})
+}
+
+/*
+ * Creates the Describe for a suite whose methods have been rewritten, and
+ * removes the suite's declarations: its fields become variables of the
+ * Describe (unless no method uses them), its helpers become funcs assigned
+ * to variables (so that they can call each other), its fixtures become
+ * BeforeEach, AfterEach, BeforeAll and AfterAll (in an Ordered Describe)
+ * and its tests become Its
+ */
+func (r *fileRewriter) createSuiteDescribe(testSuite xunitSuite, name ast.Expr) *ast.ExprStmt {
+	statements := []ast.Stmt{}
+	for _, field := range testSuite.fields {
+		// a variable nothing uses would not compile
+		if !testSuite.usesMember(field.name) {
+			continue
+		}
+
+		declaration := createVarDeclaration(field.name, field.fieldType)
+		if value, ok := testSuite.values[field.name]; ok {
+			valueSpec := declaration.Decl.(*ast.GenDecl).Specs[0].(*ast.ValueSpec)
+			valueSpec.Values = []ast.Expr{value}
+		}
+		statements = append(statements, declaration)
+	}
+
+	helpers, hooks, specs := []ast.Stmt{}, []ast.Stmt{}, []ast.Stmt{}
+	ordered := false
+	for _, method := range testSuite.methods {
+		methodName := method.Name.Name
+		switch {
+		case testSuite.isTest(method):
+			it := &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(rewriteTestName(methodName))}
+			spec := createGinkgoStatement("It", it, method.Body.List)
+			positionSpecAt(spec, method.Pos(), method.Body)
+			specs = append(specs, spec)
+		case testSuite.isHook(method):
+			kind := testSuite.style.hooks[methodName]
+			ordered = ordered || suiteLevelHooks[kind]
+			hook := createGinkgoStatement(kind, nil, method.Body.List)
+			positionSpecAt(hook, method.Pos(), method.Body)
+			hooks = append(hooks, hook)
+		default:
+			statements = append(statements, createVarDeclaration(methodName, method.Type))
+			helpers = append(helpers, &ast.AssignStmt{
+				Lhs: []ast.Expr{&ast.Ident{Name: methodName}},
+				Tok: token.ASSIGN,
+				Rhs: []ast.Expr{&ast.FuncLit{Type: method.Type, Body: method.Body}},
+			})
+		}
+	}
+
+	statements = append(statements, helpers...)
+	statements = append(statements, hooks...)
+	statements = append(statements, specs...)
+
+	describe := createGinkgoStatement("Describe", name, statements)
+	if ordered {
+		callExpr := describe.X.(*ast.CallExpr)
+		callExpr.Args = append([]ast.Expr{name, &ast.Ident{Name: "Ordered"}}, callExpr.Args[1:]...)
+	}
+
+	removed := []ast.Decl{}
+	for _, method := range testSuite.methods {
+		removed = append(removed, method)
+	}
+
+	typeDecl := testSuite.typeDecl
+	if len(typeDecl.Specs) == 1 {
+		removed = append(removed, typeDecl)
+	} else {
+		for index, spec := range typeDecl.Specs {
+			if spec == ast.Spec(testSuite.typeSpec) {
+				typeDecl.Specs = append(typeDecl.Specs[:index], typeDecl.Specs[index+1:]...)
+				break
+			}
+		}
+	}
+	r.removeDecls(removed...)
+	return describe
+}
+
+/*
+ * Whether any of the suite's rewritten methods refers to one of its fields
+ * or helpers, eg: db once s.db became db
+ */
+func (testSuite xunitSuite) usesMember(name string) bool {
+	for _, method := range testSuite.methods {
+		if countUnqualifiedIdentifiers(method.Body, name) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ * Removes top level declarations from the file
+ */
+func (r *fileRewriter) removeDecls(decls ...ast.Decl) {
+	removed := map[ast.Decl]bool{}
+	for _, decl := range decls {
+		removed[decl] = true
+	}
+
+	remaining := []ast.Decl{}
+	for _, decl := range r.rootNode.Decls {
+		if !removed[decl] {
+			remaining = append(remaining, decl)
+		}
+	}
+	r.rootNode.Decls = remaining
+}
+
+/*
+ * Counts the identifiers called name that are not a field or method
+ * selected from something else, eg: db but not s.db
+ */
+func countUnqualifiedIdentifiers(root ast.Node, name string) int {
+	selected := 0
+	ast.Inspect(root, func(node ast.Node) bool {
+		if selectorExpr, ok := node.(*ast.SelectorExpr); ok && selectorExpr.Sel.Name == name {
+			selected++
+		}
+		return true
+	})
+	return countIdentifiers(root, name) - selected
+}
+
+/*
+ * The name path is imported as in this file, or packageName when the
+ * import is not renamed
+ */
+func importedName(rootNode *ast.File, path, packageName string) (string, bool) {
+	for _, importSpec := range rootNode.Imports {
+		importPath, err := strconv.Unquote(importSpec.Path.Value)
+		if err != nil || importPath != path {
+			continue
+		}
+
+		if importSpec.Name != nil {
+			return importSpec.Name.Name, true
+		}
+		return packageName, true
+	}
+	return "", false
+}
@@ -0,0 +1,714 @@
+package converter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+)
+
+/*
+ * A table driven test, eg:
+ *   tests := []struct{ name string; input, want int }{ {"one", 1, 2} }
+ *   for _, tc := range tests {
+ *     t.Run(tc.name, func(t *testing.T) { ... })
+ *   }
+ */
+type tableTest struct {
+	// the statement declaring the table (if it is not ranged over directly)
+	declaration ast.Stmt
+	loop        *ast.RangeStmt
+	literal     *ast.CompositeLit
+
+	// the loop's key and value variables, eg: _ and tc
+	keyName   string
+	valueName string
+	keyType   ast.Expr
+
+	fields     []tableField
+	subtestT   string
+	subtestRun *ast.CallExpr
+	body       *ast.BlockStmt
+}
+
+type tableField struct {
+	name      string
+	fieldType ast.Expr
+}
+
+/*
+ * Converts a test (or subtest) whose body ends by ranging over a table
+ * literal with t.Run into a DescribeTable with one Entry per row. Any setup
+ * before the table goes into a BeforeEach, with the DescribeTable inside a
+ * Describe. ok is false when the body is not a table driven test, or when it
+ * cannot be converted statically (reporting why), eg: when more statements
+ * follow the table's loop.
+ */
+func (r *fileRewriter) createTableForBody(description ast.Expr, body *ast.BlockStmt, testingT string) (spec *ast.ExprStmt, ok bool) {
+	if body == nil || testingT == "" || len(body.List) == 0 {
+		return nil, false
+	}
+
+	for _, statement := range body.List[:len(body.List)-1] {
+		loop, ok := statement.(*ast.RangeStmt)
+		if ok && containsTestingTRunCall(loop.Body, testingT) {
+			r.report(loop.Pos(), "could not convert this table driven test statically: statements follow the loop; left the test as a single It")
+			return nil, false
+		}
+	}
+
+	loop, ok := body.List[len(body.List)-1].(*ast.RangeStmt)
+	if !ok || !containsTestingTRunCall(loop.Body, testingT) {
+		return nil, false
+	}
+
+	table, reason := r.findTable(body, loop, testingT)
+	if reason == "" {
+		reason = r.checkTableBody(table)
+	}
+	if reason != "" {
+		r.report(loop.Pos(), "could not convert this table driven test statically: %s; left the test as a single It", reason)
+		return nil, false
+	}
+
+	entries, reason := r.tableEntries(table)
+	if reason != "" {
+		r.report(loop.Pos(), "could not convert this table driven test statically: %s; left the test as a single It", reason)
+		return nil, false
+	}
+
+	setup := []ast.Stmt{}
+	for _, statement := range body.List[:len(body.List)-1] {
+		if statement != table.declaration {
+			setup = append(setup, statement)
+		}
+	}
+
+	if name, ok := identifierDeclaredIn(setup, table.literal); ok {
+		r.report(loop.Pos(), "could not convert this table driven test statically: the table refers to '%s', which is set up by the test; left the test as a single It", name)
+		return nil, false
+	}
+
+	statements, ok := r.createSetupNodes(setup)
+	if !ok {
+		return nil, false
+	}
+
+	if r.target.hasCleanupAndHelper {
+		replaceCleanupAndHelperCalls(table.body, table.subtestT)
+	}
+	replaceTestingTsWithMrT(table.body, table.subtestT, r.target.backend)
+	r.replaceRowFieldsWithParams(table)
+	r.dropTableComments(table, entries)
+	r.usesTables = true
+
+	if len(setup) == 0 {
+		return createDescribeTable(description, r.tableBodyFunc(table), entries), true
+	}
+
+	tableName := &ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf("%q", tableDescription(table))}
+	statements = append(statements, createDescribeTable(tableName, r.tableBodyFunc(table), entries))
+	return createGinkgoStatement("Describe", description, statements), true
+}
+
+/*
+ * Drops the comments inside the table literal (eg: on its fields or rows),
+ * reporting that it did. The Entries print after the table's body, since
+ * the rows come from before it, so their comments would print in the body.
+ * An Entry whose row had a comment above it starts where the comment did,
+ * so that no blank line is left in its place.
+ */
+func (r *fileRewriter) dropTableComments(table tableTest, entries []ast.Expr) {
+	var declaration ast.Node = table.literal
+	if table.declaration != nil {
+		declaration = table.declaration
+	}
+
+	comments := []*ast.CommentGroup{}
+	for _, group := range r.rootNode.Comments {
+		if group.Pos() < declaration.Pos() || group.End() > declaration.End() {
+			comments = append(comments, group)
+			continue
+		}
+
+		trailing := false
+		for _, entry := range entries {
+			trailing = trailing || lineOf(r.fileSet, entry.(*ast.CallExpr).Rparen) == lineOf(r.fileSet, group.Pos())
+		}
+
+		for _, entry := range entries {
+			name := entry.(*ast.CallExpr).Fun.(*ast.Ident)
+			if !trailing && lineOf(r.fileSet, group.End()).line+1 == lineOf(r.fileSet, name.NamePos).line {
+				name.NamePos = group.Pos()
+			}
+		}
+	}
+
+	if len(comments) < len(r.rootNode.Comments) {
+		r.report(declaration.Pos(), "dropped the comments in this table, which have no place next to the Entries of its DescribeTable")
+		r.rootNode.Comments = comments
+	}
+}
+
+/*
+ * Finds the table literal that the loop ranges over, and the t.Run call
+ * in the loop. Returns the reason when the loop is not a table we can convert.
+ */
+func (r *fileRewriter) findTable(body *ast.BlockStmt, loop *ast.RangeStmt, testingT string) (table tableTest, reason string) {
+	table.loop = loop
+
+	if loop.Tok != token.DEFINE || loop.Value == nil {
+		return table, "the loop must declare a variable for each row, eg: for _, tc := range tests"
+	}
+
+	valueIdent, ok := loop.Value.(*ast.Ident)
+	if !ok {
+		return table, "the loop must declare a variable for each row, eg: for _, tc := range tests"
+	}
+	table.valueName = valueIdent.Name
+
+	if keyIdent, ok := loop.Key.(*ast.Ident); ok && keyIdent.Name != "_" {
+		table.keyName = keyIdent.Name
+	}
+
+	switch rangeExpr := loop.X.(type) {
+	case *ast.CompositeLit:
+		table.literal = rangeExpr
+	case *ast.Ident:
+		table.declaration, table.literal = tableDeclaration(body.List[:len(body.List)-1], rangeExpr.Name)
+		if table.literal == nil {
+			return table, fmt.Sprintf("'%s' is not declared as a slice or map literal in the test", rangeExpr.Name)
+		}
+
+		if countIdentifiers(body, rangeExpr.Name) != 2 {
+			return table, fmt.Sprintf("'%s' is used for more than ranging over its rows", rangeExpr.Name)
+		}
+	default:
+		return table, "the loop does not range over a slice or map literal"
+	}
+
+	var rowType ast.Expr
+	switch literalType := table.literal.Type.(type) {
+	case *ast.ArrayType:
+		rowType = literalType.Elt
+		if table.keyName != "" {
+			return table, "the row index is used"
+		}
+	case *ast.MapType:
+		rowType = literalType.Value
+		table.keyType = literalType.Key
+	default:
+		return table, "the loop does not range over a slice or map literal"
+	}
+
+	structType, ok := r.structTypeNamed(rowType)
+	if !ok {
+		return table, "the rows are not structs declared in this file"
+	}
+
+	for _, field := range structType.Fields.List {
+		if len(field.Names) == 0 {
+			return table, "the rows have embedded fields"
+		}
+
+		for _, name := range field.Names {
+			table.fields = append(table.fields, tableField{name: name.Name, fieldType: field.Type})
+		}
+	}
+
+	statements := []ast.Stmt{}
+	for _, statement := range loop.Body.List {
+		if !isCopyOfLoopVariable(statement, table.valueName) {
+			statements = append(statements, statement)
+		}
+	}
+
+	if len(statements) != 1 || !isTestingTRunCall(statements[0], testingT) {
+		return table, "the loop must only call t.Run"
+	}
+
+	table.subtestRun = statements[0].(*ast.ExprStmt).X.(*ast.CallExpr)
+	if len(table.subtestRun.Args) != 2 {
+		return table, "t.Run must be passed a name and a func literal"
+	}
+
+	funcLit, ok := table.subtestRun.Args[1].(*ast.FuncLit)
+	if !ok || len(funcLit.Type.Params.List) != 1 || !isTestingTPointer(funcLit.Type.Params.List[0].Type) {
+		return table, "t.Run must be passed a name and a func literal"
+	}
+
+	table.body = funcLit.Body
+	if names := funcLit.Type.Params.List[0].Names; len(names) > 0 {
+		table.subtestT = names[0].Name
+	}
+	return table, ""
+}
+
+/*
+ * Checks that the subtest only refers to its row through its fields, and
+ * that those fields can become params without clashing with anything else
+ */
+func (r *fileRewriter) checkTableBody(table tableTest) string {
+	fieldSelectors := map[*ast.Ident]bool{}
+	selectedNames := map[*ast.Ident]bool{}
+	ast.Inspect(table.body, func(node ast.Node) bool {
+		selectorExpr, ok := node.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+
+		selectedNames[selectorExpr.Sel] = true
+		if ident, ok := selectorExpr.X.(*ast.Ident); ok && ident.Name == table.valueName {
+			fieldSelectors[ident] = true
+		}
+		return true
+	})
+
+	fieldNames := map[string]bool{}
+	for _, field := range table.fields {
+		fieldNames[field.name] = true
+	}
+
+	reason := ""
+	ast.Inspect(table.body, func(node ast.Node) bool {
+		ident, ok := node.(*ast.Ident)
+		if !ok || reason != "" || selectedNames[ident] {
+			return true
+		}
+
+		if ident.Name == table.valueName && !fieldSelectors[ident] {
+			reason = fmt.Sprintf("'%s' is used as a whole instead of through its fields", table.valueName)
+		} else if fieldNames[ident.Name] {
+			reason = fmt.Sprintf("the field '%s' would clash with another '%s' in the test", ident.Name, ident.Name)
+		}
+		return true
+	})
+	return reason
+}
+
+/*
+ * Creates an Entry(name, [key], fields...) for each row of the table
+ */
+func (r *fileRewriter) tableEntries(table tableTest) (entries []ast.Expr, reason string) {
+	for _, element := range table.literal.Elts {
+		var key ast.Expr
+		row := element
+		if keyValueExpr, ok := element.(*ast.KeyValueExpr); ok {
+			if table.keyType == nil {
+				return nil, "the rows of an array are indexed"
+			}
+			key, row = keyValueExpr.Key, keyValueExpr.Value
+		}
+
+		rowLiteral, ok := row.(*ast.CompositeLit)
+		if !ok {
+			return nil, "a row is not a struct literal"
+		}
+
+		values, reason := tableRowValues(table, rowLiteral)
+		if reason != "" {
+			return nil, reason
+		}
+
+		name, reason := tableEntryName(table, key, values)
+		if reason != "" {
+			return nil, reason
+		}
+
+		args := []ast.Expr{name}
+		if table.keyName != "" {
+			args = append(args, typedValue(key, table.keyType))
+		}
+		args = append(args, values...)
+		// positioned at the row, so that each Entry is printed on its own line
+		entry := &ast.CallExpr{Fun: &ast.Ident{NamePos: element.Pos(), Name: "Entry"}, Args: args, Rparen: element.End()}
+		entries = append(entries, entry)
+	}
+
+	if len(entries) == 0 {
+		return nil, "the table has no rows"
+	}
+	return entries, ""
+}
+
+/*
+ * The values for each of the table's fields in a row, in the order the
+ * fields were declared, converted to the fields' types where they are
+ * constants. Fields missing from keyed rows get their zero value.
+ */
+func tableRowValues(table tableTest, row *ast.CompositeLit) (values []ast.Expr, reason string) {
+	if len(row.Elts) == 0 || !isKeyedLiteral(row) {
+		if len(row.Elts) != len(table.fields) {
+			return nil, "a row does not set every field"
+		}
+
+		for index, value := range row.Elts {
+			values = append(values, typedValue(value, table.fields[index].fieldType))
+		}
+		return values, ""
+	}
+
+	keyed := map[string]ast.Expr{}
+	for _, element := range row.Elts {
+		keyValueExpr := element.(*ast.KeyValueExpr)
+		ident, ok := keyValueExpr.Key.(*ast.Ident)
+		if !ok {
+			return nil, "a row's keys are not field names"
+		}
+		keyed[ident.Name] = keyValueExpr.Value
+	}
+
+	for _, field := range table.fields {
+		value, ok := keyed[field.name]
+		if !ok {
+			value, ok = zeroValueForType(field.fieldType)
+			if !ok {
+				return nil, fmt.Sprintf("a row does not set '%s', and its zero value is unknown", field.name)
+			}
+		}
+		values = append(values, typedValue(value, field.fieldType))
+	}
+	return values, ""
+}
+
+/*
+ * The Entry's description: whichever field (or map key) names the subtest
+ */
+func tableEntryName(table tableTest, key ast.Expr, values []ast.Expr) (ast.Expr, string) {
+	switch name := table.subtestRun.Args[0].(type) {
+	case *ast.Ident:
+		if name.Name == table.keyName && key != nil {
+			return key, ""
+		}
+	case *ast.SelectorExpr:
+		ident, ok := name.X.(*ast.Ident)
+		if !ok || ident.Name != table.valueName {
+			break
+		}
+
+		for index, field := range table.fields {
+			if field.name == name.Sel.Name {
+				return values[index], ""
+			}
+		}
+	}
+	return nil, "the subtest's name is not a field of the table"
+}
+
+/*
+ * Rewrites tc.input as input in the subtest
+ */
+func (r *fileRewriter) replaceRowFieldsWithParams(table tableTest) {
+	replaceExpressions(table.body, func(expr ast.Expr) ast.Expr {
+		selectorExpr, ok := expr.(*ast.SelectorExpr)
+		if !ok {
+			return expr
+		}
+
+		ident, ok := selectorExpr.X.(*ast.Ident)
+		if !ok || ident.Name != table.valueName {
+			return expr
+		}
+		return &ast.Ident{NamePos: ident.NamePos, Name: selectorExpr.Sel.Name}
+	})
+}
+
+/*
+ * eg: func(input int, want int) { ... }
+ */
+func (r *fileRewriter) tableBodyFunc(table tableTest) *ast.FuncLit {
+	params := &ast.FieldList{}
+	if table.keyName != "" {
+		params.List = append(params.List, &ast.Field{Names: []*ast.Ident{{Name: table.keyName}}, Type: table.keyType})
+	}
+
+	for _, field := range table.fields {
+		params.List = append(params.List, &ast.Field{Names: []*ast.Ident{{Name: field.name}}, Type: field.fieldType})
+	}
+
+	return &ast.FuncLit{Type: &ast.FuncType{Params: params}, Body: table.body}
+}
+
+/*
+ * eg: DescribeTable("adding", func(a, b int) { ... }, Entry(...), ...)
+ */
+func createDescribeTable(description ast.Expr, body *ast.FuncLit, entries []ast.Expr) *ast.ExprStmt {
+	args := append([]ast.Expr{description, body}, entries...)
+	callExpr := &ast.CallExpr{Fun: &ast.Ident{Name: "DescribeTable"}, Args: args}
+	return &ast.ExprStmt{X: callExpr}
+}
+
+/*
+ * The name of the table variable, or "cases" when it was ranged over directly
+ */
+func tableDescription(table tableTest) string {
+	if ident, ok := table.loop.X.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return "cases"
+}
+
+/*
+ * Finds the statement declaring name as a slice or map literal, eg:
+ *   tests := []struct{...}{...}
+ *   var tests = map[string]testCase{...}
+ */
+func tableDeclaration(statements []ast.Stmt, name string) (ast.Stmt, *ast.CompositeLit) {
+	for _, statement := range statements {
+		var value ast.Expr
+		switch statement := statement.(type) {
+		case *ast.AssignStmt:
+			if statement.Tok != token.DEFINE || len(statement.Lhs) != 1 || len(statement.Rhs) != 1 {
+				continue
+			}
+
+			if ident, ok := statement.Lhs[0].(*ast.Ident); ok && ident.Name == name {
+				value = statement.Rhs[0]
+			}
+		case *ast.DeclStmt:
+			genDecl, ok := statement.Decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.VAR || len(genDecl.Specs) != 1 {
+				continue
+			}
+
+			valueSpec := genDecl.Specs[0].(*ast.ValueSpec)
+			if len(valueSpec.Names) == 1 && len(valueSpec.Values) == 1 && valueSpec.Names[0].Name == name {
+				value = valueSpec.Values[0]
+			}
+		}
+
+		if literal, ok := value.(*ast.CompositeLit); ok {
+			return statement, literal
+		}
+	}
+	return nil, nil
+}
+
+/*
+ * The struct type of a table's rows: either declared inline, or a named
+ * type declared in this file
+ */
+func (r *fileRewriter) structTypeNamed(rowType ast.Expr) (*ast.StructType, bool) {
+	switch rowType := rowType.(type) {
+	case *ast.StructType:
+		return rowType, true
+	case *ast.Ident:
+		for _, decl := range r.rootNode.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				if typeSpec.Name.Name != rowType.Name {
+					continue
+				}
+
+				structType, ok := typeSpec.Type.(*ast.StructType)
+				return structType, ok
+			}
+		}
+	}
+	return nil, false
+}
+
+/*
+ * eg: tc := tc
+ */
+func isCopyOfLoopVariable(statement ast.Stmt, name string) bool {
+	assignStmt, ok := statement.(*ast.AssignStmt)
+	if !ok || assignStmt.Tok != token.DEFINE || len(assignStmt.Lhs) != 1 || len(assignStmt.Rhs) != 1 {
+		return false
+	}
+
+	lhs, lhsOk := assignStmt.Lhs[0].(*ast.Ident)
+	rhs, rhsOk := assignStmt.Rhs[0].(*ast.Ident)
+	return lhsOk && rhsOk && lhs.Name == name && rhs.Name == name
+}
+
+func isKeyedLiteral(literal *ast.CompositeLit) bool {
+	for _, element := range literal.Elts {
+		if _, ok := element.(*ast.KeyValueExpr); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func containsTestingTRunCall(block *ast.BlockStmt, testingT string) bool {
+	for _, statement := range block.List {
+		if isTestingTRunCall(statement, testingT) {
+			return true
+		}
+	}
+	return false
+}
+
+func countIdentifiers(root ast.Node, name string) (count int) {
+	ast.Inspect(root, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Ident); ok && ident.Name == name {
+			count++
+		}
+		return true
+	})
+	return
+}
+
+/*
+ * Returns the first identifier in node that is declared by the statements
+ */
+func identifierDeclaredIn(statements []ast.Stmt, node ast.Node) (string, bool) {
+	declared := map[string]bool{}
+	for _, statement := range statements {
+		switch statement := statement.(type) {
+		case *ast.AssignStmt:
+			if statement.Tok != token.DEFINE {
+				continue
+			}
+			for _, lhs := range statement.Lhs {
+				if ident, ok := lhs.(*ast.Ident); ok {
+					declared[ident.Name] = true
+				}
+			}
+		case *ast.DeclStmt:
+			genDecl, ok := statement.Decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				if valueSpec, ok := spec.(*ast.ValueSpec); ok {
+					for _, name := range valueSpec.Names {
+						declared[name.Name] = true
+					}
+				}
+			}
+		}
+	}
+
+	found := ""
+	ast.Inspect(node, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Ident); ok && found == "" && declared[ident.Name] {
+			found = ident.Name
+		}
+		return true
+	})
+	return found, found != ""
+}
+
+/*
+ * The zero value of a type, for the types where it can be known without
+ * type checking the package
+ */
+func zeroValueForType(fieldType ast.Expr) (ast.Expr, bool) {
+	switch fieldType := fieldType.(type) {
+	case *ast.StarExpr, *ast.MapType, *ast.FuncType, *ast.ChanType, *ast.InterfaceType:
+		return &ast.Ident{Name: "nil"}, true
+	case *ast.ArrayType:
+		if fieldType.Len == nil {
+			return &ast.Ident{Name: "nil"}, true
+		}
+	case *ast.Ident:
+		switch fieldType.Name {
+		case "string":
+			return &ast.BasicLit{Kind: token.STRING, Value: `""`}, true
+		case "bool":
+			return &ast.Ident{Name: "false"}, true
+		case "error":
+			return &ast.Ident{Name: "nil"}, true
+		case "int", "int8", "int16", "int32", "int64",
+			"uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+			"float32", "float64", "complex64", "complex128", "byte", "rune":
+			return &ast.BasicLit{Kind: token.INT, Value: "0"}, true
+		}
+	}
+	return nil, false
+}
+
+/*
+ * ginkgo passes an Entry's values to the table's func with reflect, which
+ * panics when a constant's default type (eg: int for 2) is not the type of
+ * the param (eg: float64). So constants are converted to their field's
+ * type when it is not their default type, eg: float64(2)
+ */
+func typedValue(value, fieldType ast.Expr) ast.Expr {
+	var conversion ast.Expr
+	switch fieldType := fieldType.(type) {
+	case *ast.Ident:
+		conversion = &ast.Ident{Name: fieldType.Name}
+	case *ast.SelectorExpr:
+		packageIdent, ok := fieldType.X.(*ast.Ident)
+		if !ok {
+			return value
+		}
+		conversion = &ast.SelectorExpr{X: &ast.Ident{Name: packageIdent.Name}, Sel: &ast.Ident{Name: fieldType.Sel.Name}}
+	default:
+		return value
+	}
+
+	defaultType, ok := constantDefaultType(value)
+	if !ok {
+		return value
+	}
+
+	if ident, ok := conversion.(*ast.Ident); ok && (ident.Name == defaultType || ident.Name == "int32" && defaultType == "rune") {
+		return value
+	}
+	return &ast.CallExpr{Fun: conversion, Args: []ast.Expr{value}}
+}
+
+/*
+ * The type an untyped constant expression has when nothing converts it,
+ * eg: float64 for 1 + 0.5
+ */
+func constantDefaultType(expr ast.Expr) (string, bool) {
+	switch expr := expr.(type) {
+	case *ast.BasicLit:
+		switch expr.Kind {
+		case token.INT:
+			return "int", true
+		case token.FLOAT:
+			return "float64", true
+		case token.IMAG:
+			return "complex128", true
+		case token.CHAR:
+			return "rune", true
+		case token.STRING:
+			return "string", true
+		}
+	case *ast.Ident:
+		if expr.Name == "true" || expr.Name == "false" {
+			return "bool", true
+		}
+	case *ast.ParenExpr:
+		return constantDefaultType(expr.X)
+	case *ast.UnaryExpr:
+		return constantDefaultType(expr.X)
+	case *ast.BinaryExpr:
+		x, ok := constantDefaultType(expr.X)
+		if !ok {
+			return "", false
+		}
+
+		y, ok := constantDefaultType(expr.Y)
+		if !ok {
+			return "", false
+		}
+
+		switch expr.Op {
+		case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+			return "bool", true
+		case token.SHL, token.SHR:
+			return x, true
+		}
+
+		// mixing kinds of numbers gives the later kind, eg: 1 + 0.5
+		kinds := []string{"int", "rune", "float64", "complex128"}
+		for index := len(kinds) - 1; index >= 0; index-- {
+			if x == kinds[index] || y == kinds[index] {
+				return kinds[index], true
+			}
+		}
+		return x, true
+	}
+	return "", false
+}
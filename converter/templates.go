@@ -0,0 +1,153 @@
+package converter
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build/constraint"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+/*
+ * TemplateData holds the values available to suite file and spec templates.
+ */
+type TemplateData struct {
+	// The package the generated code belongs to, eg: foo or foo_test
+	PackageName string
+
+	// The package's import path. Empty when converting a single file.
+	ImportPath string
+
+	// eg: MyPackage, as used in the suite's func TestMyPackage(t *testing.T)
+	FormattedName string
+
+	// eg: MyPackage Suite
+	SuiteTitle string
+
+	// Build tags found in //go:build or // +build lines of the package's
+	// test files. For the spec template, only those of the converted file.
+	BuildTags []string
+}
+
+func templateDataForPackage(pkg *goPackage) (TemplateData, error) {
+	data := TemplateData{
+		PackageName:   strings.TrimSuffix(pkg.Name, "_test"),
+		ImportPath:    pkg.ImportPath,
+		FormattedName: prettifyPackageName(filepath.Base(pkg.Dir)),
+	}
+	data.SuiteTitle = data.FormattedName + " Suite"
+
+	for _, file := range testFilesInPackage(pkg) {
+		src, err := ioutil.ReadFile(file)
+		if err != nil {
+			return data, fmt.Errorf("Error reading test file '%s':\n%s\n", file, err.Error())
+		}
+		data.BuildTags = append(data.BuildTags, buildTagsInSource(src)...)
+	}
+	data.BuildTags = uniqueStrings(data.BuildTags)
+
+	return data, nil
+}
+
+/*
+ * Finds the tags used by build constraints at the top of a go file
+ */
+func buildTagsInSource(src []byte) (tags []string) {
+	scanner := bufio.NewScanner(bytes.NewReader(src))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "package ") {
+			break
+		}
+
+		if !constraint.IsGoBuild(line) && !constraint.IsPlusBuild(line) {
+			continue
+		}
+
+		expr, err := constraint.Parse(line)
+		if err != nil {
+			continue
+		}
+		tags = append(tags, tagsInConstraint(expr)...)
+	}
+	return uniqueStrings(tags)
+}
+
+func tagsInConstraint(expr constraint.Expr) []string {
+	switch expr := expr.(type) {
+	case *constraint.TagExpr:
+		return []string{expr.Tag}
+	case *constraint.NotExpr:
+		return tagsInConstraint(expr.X)
+	case *constraint.AndExpr:
+		return append(tagsInConstraint(expr.X), tagsInConstraint(expr.Y)...)
+	case *constraint.OrExpr:
+		return append(tagsInConstraint(expr.X), tagsInConstraint(expr.Y)...)
+	}
+	return nil
+}
+
+func uniqueStrings(values []string) (unique []string) {
+	seen := map[string]bool{}
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	sort.Strings(unique)
+	return
+}
+
+func renderTemplate(name, text string, data TemplateData) ([]byte, error) {
+	parsed, err := template.New(name).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s template:\n%s\n", name, err.Error())
+	}
+
+	var buffer bytes.Buffer
+	err = parsed.Execute(&buffer, data)
+	if err != nil {
+		return nil, fmt.Errorf("error rendering %s template for '%s':\n%s\n", name, data.PackageName, err.Error())
+	}
+	return buffer.Bytes(), nil
+}
+
+/*
+ * Renders the spec template (eg: Describe("...", func() {})) and parses it
+ * into the container node that the converted Its are added to.
+ * The template must be a call expression with a func literal argument.
+ */
+func createDescribeBlockFromTemplate(fileSet *token.FileSet, text string, data TemplateData) (*ast.ExprStmt, error) {
+	rendered, err := renderTemplate("spec", text, data)
+	if err != nil {
+		return nil, err
+	}
+
+	expr, err := parser.ParseExprFrom(fileSet, "spec template", rendered, 0)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing rendered spec template:\n%s\n%s\n", rendered, err.Error())
+	}
+
+	callExpr, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return nil, fmt.Errorf("expected the spec template to be a call like Describe(\"...\", func() {}), got:\n%s\n", rendered)
+	}
+
+	// the container is laid out with the code it holds, like the default one
+	clearPositions(callExpr)
+
+	describe := &ast.ExprStmt{X: callExpr}
+	_, err = blockStatementFromDescribe(describe)
+	if err != nil {
+		return nil, fmt.Errorf("expected the spec template to pass a func() {} to %s:\n%s\n", rendered, err.Error())
+	}
+	return describe, nil
+}
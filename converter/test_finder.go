@@ -0,0 +1,227 @@
+package converter
+
+import (
+	"go/ast"
+	"go/token"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+/*
+ * Given a root node, walks its top level statements and returns
+ * points to function nodes to rewrite as It statements.
+ * These functions, according to Go testing convention, must be named
+ * TestWithCamelCasedName and receive a single *testing.T argument.
+ * A ginkgo suite's entry point is not one of them.
+ */
+func findTestFuncs(rootNode *ast.File) (testsToRewrite []*ast.FuncDecl) {
+	testNameRegexp := regexp.MustCompile("^Test[A-Z].+")
+
+	ast.Inspect(rootNode, func(node ast.Node) bool {
+		if node == nil {
+			return false
+		}
+
+		switch node := node.(type) {
+		case *ast.FuncDecl:
+			matches := testNameRegexp.MatchString(node.Name.Name)
+
+			if matches && receivesTestingT(node) && !isGinkgoSuiteEntryPoint(node) {
+				testsToRewrite = append(testsToRewrite, node)
+			}
+		}
+
+		return true
+	})
+
+	return
+}
+
+/*
+ * Whether go test runs the func as a test, eg: TestSomething(t *testing.T),
+ * or gocheck's Test(t *testing.T)
+ */
+func isTestFunc(node *ast.FuncDecl) bool {
+	name := strings.TrimPrefix(node.Name.Name, "Test")
+	if node.Recv != nil || name == node.Name.Name || !receivesTestingT(node) {
+		return false
+	}
+
+	first, _ := utf8.DecodeRuneInString(name)
+	return name == "" || !unicode.IsLower(first)
+}
+
+/*
+ * Like findTestFuncs, for benchmarks named BenchmarkWithCamelCasedName
+ * that receive a single *testing.B argument
+ */
+func findBenchmarkFuncs(rootNode *ast.File) (benchmarks []*ast.FuncDecl) {
+	benchmarkNameRegexp := regexp.MustCompile("^Benchmark[A-Z].+")
+
+	for _, decl := range rootNode.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || funcDecl.Recv != nil || funcDecl.Body == nil || !benchmarkNameRegexp.MatchString(funcDecl.Name.Name) {
+			continue
+		}
+
+		params := funcDecl.Type.Params.List
+		if len(params) == 1 && len(params[0].Names) <= 1 && isTestingBPointer(params[0].Type) {
+			benchmarks = append(benchmarks, funcDecl)
+		}
+	}
+	return
+}
+
+/*
+ * Finds the package's TestMain(m *testing.M), if this file declares it
+ */
+func findTestMain(rootNode *ast.File) *ast.FuncDecl {
+	for _, decl := range rootNode.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || funcDecl.Recv != nil || funcDecl.Name.Name != "TestMain" || funcDecl.Body == nil {
+			continue
+		}
+
+		params := funcDecl.Type.Params.List
+		if len(params) == 1 && isTestingMPointer(params[0].Type) {
+			return funcDecl
+		}
+	}
+	return nil
+}
+
+/*
+ * Finds the Describe a file converted earlier declares its specs in, ie:
+ *   func init() { Describe(...) }
+ * for ginkgo v1, or
+ *   var _ = Describe(...)
+ * so that the file's new specs join the others instead of a second container
+ */
+func findTopLevelDescribe(rootNode *ast.File) (*ast.ExprStmt, bool) {
+	for _, decl := range rootNode.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Recv != nil || decl.Name.Name != "init" || decl.Body == nil {
+				continue
+			}
+
+			for _, statement := range decl.Body.List {
+				exprStmt, ok := statement.(*ast.ExprStmt)
+				if ok && isDescribeCall(exprStmt.X) {
+					return exprStmt, true
+				}
+			}
+		case *ast.GenDecl:
+			if decl.Tok != token.VAR {
+				continue
+			}
+
+			for _, spec := range decl.Specs {
+				valueSpec, ok := spec.(*ast.ValueSpec)
+				if !ok || len(valueSpec.Names) != 1 || valueSpec.Names[0].Name != "_" || len(valueSpec.Values) != 1 {
+					continue
+				}
+
+				if isDescribeCall(valueSpec.Values[0]) {
+					return &ast.ExprStmt{X: valueSpec.Values[0]}, true
+				}
+			}
+		}
+	}
+	return nil, false
+}
+
+/*
+ * Whether expr calls Describe (or ginkgo.Describe) with a func holding its specs
+ */
+func isDescribeCall(expr ast.Expr) bool {
+	callExpr, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+
+	var name string
+	switch fun := callExpr.Fun.(type) {
+	case *ast.Ident:
+		name = fun.Name
+	case *ast.SelectorExpr:
+		name = fun.Sel.Name
+	}
+
+	if name != "Describe" {
+		return false
+	}
+
+	for _, arg := range callExpr.Args {
+		if _, ok := arg.(*ast.FuncLit); ok {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ * convenience function that looks at args to a function and determines if its
+ * params include an argument of type  *testing.T
+ */
+func receivesTestingT(node *ast.FuncDecl) bool {
+	if len(node.Type.Params.List) != 1 {
+		return false
+	}
+
+	base, ok := node.Type.Params.List[0].Type.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+
+	intermediate, ok := base.X.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+
+	packageIdent, ok := intermediate.X.(*ast.Ident)
+	if !ok {
+		return false
+	}
+
+	isTestingPackage := packageIdent.Name == "testing"
+	isTestingT := intermediate.Sel.Name == "T"
+
+	return isTestingPackage && isTestingT
+}
+
+/*
+ * A ginkgo suite's entry point is a regular TestXxx(t *testing.T) func that
+ * hands control over to ginkgo by calling RunSpecs (or one of its variants).
+ * These must stay as they are, so that `go test` can run the suite.
+ */
+func isGinkgoSuiteEntryPoint(node *ast.FuncDecl) bool {
+	if node.Body == nil {
+		return false
+	}
+
+	found := false
+	ast.Inspect(node.Body, func(node ast.Node) bool {
+		callExpr, ok := node.(*ast.CallExpr)
+		if !ok {
+			return !found
+		}
+
+		var name string
+		switch fun := callExpr.Fun.(type) {
+		case *ast.Ident:
+			name = fun.Name
+		case *ast.SelectorExpr:
+			name = fun.Sel.Name
+		}
+
+		if strings.HasPrefix(name, "RunSpecs") {
+			found = true
+		}
+		return !found
+	})
+
+	return found
+}
@@ -0,0 +1,352 @@
+package converter
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+const hoistingIntoSuiteHooks = "to share it between BeforeSuite and AfterSuite; left TestMain as it is"
+
+/*
+ * The statements of a TestMain, split around its call to m.Run()
+ */
+type testMainParts struct {
+	before []ast.Stmt
+	after  []ast.Stmt
+
+	// flags defined before m.Run(), eg: flag.StringVar(&addr, "addr", "", "")
+	flags []ast.Stmt
+
+	// calls deferred before m.Run(), when ginkgo has no DeferCleanup
+	deferred []*ast.DeferStmt
+}
+
+/*
+ * Converts the package's TestMain(m *testing.M), since the ginkgo suite's
+ * test func now runs the tests, eg:
+ *   func TestMain(m *testing.M) {
+ *     server = startServer()
+ *     code := m.Run()
+ *     server.Stop()
+ *     os.Exit(code)
+ *   }
+ * becomes var _ = BeforeSuite(func() { server = startServer() }) and
+ * var _ = AfterSuite(func() { server.Stop() }). Deferred calls become
+ * DeferCleanups in the BeforeSuite with ginkgo v2, and are made at the end
+ * of the AfterSuite with v1.
+ * flag.Parse() is dropped: `go test` has parsed the flags by the time the
+ * suite runs. The flags TestMain defines move into an init func, so that
+ * they are still defined before then.
+ */
+func (r *fileRewriter) convertTestMain() error {
+	testMain := findTestMain(r.rootNode)
+	if testMain == nil {
+		return nil
+	}
+
+	parts, reason := r.splitTestMain(testMain)
+	if reason != "" {
+		r.report(testMain.Pos(), "could not convert TestMain: %s; left it as it is", reason)
+		return nil
+	}
+
+	afterSuite := parts.after
+	for index := len(parts.deferred) - 1; index >= 0; index-- {
+		afterSuite = append(afterSuite, &ast.ExprStmt{X: parts.deferred[index].Call})
+	}
+
+	declarations, beforeSuite, ok := r.shareDeclarations(parts.before, afterSuite)
+	if !ok {
+		return nil
+	}
+
+	decls := []ast.Decl{}
+	if len(parts.flags) > 0 {
+		initFunc := createInitBlock()
+		initFunc.Body.List = parts.flags
+		decls = append(decls, initFunc)
+	}
+
+	for _, declaration := range declarations {
+		decls = append(decls, declaration.(*ast.DeclStmt).Decl)
+	}
+
+	if len(beforeSuite) > 0 {
+		decls = append(decls, createTopLevelContainer(createGinkgoStatement("BeforeSuite", nil, beforeSuite)))
+	}
+
+	if len(afterSuite) > 0 {
+		decls = append(decls, createTopLevelContainer(createGinkgoStatement("AfterSuite", nil, afterSuite)))
+	}
+
+	for index, decl := range r.rootNode.Decls {
+		if decl == testMain {
+			rest := append(decls, r.rootNode.Decls[index+1:]...)
+			r.rootNode.Decls = append(r.rootNode.Decls[:index], rest...)
+			break
+		}
+	}
+
+	for _, path := range []string{"os", "flag"} {
+		if packageName, ok := importedName(r.rootNode, path, path); ok {
+			removeImportIfUnused(r.rootNode, packageName, path)
+		}
+	}
+	return nil
+}
+
+/*
+ * Splits TestMain around the statement calling m.Run(), which must be one of
+ *   os.Exit(m.Run())
+ *   m.Run()
+ *   code := m.Run() (with os.Exit(code) as the last statement)
+ * reason explains why TestMain cannot be converted, when it cannot be.
+ */
+func (r *fileRewriter) splitTestMain(testMain *ast.FuncDecl) (parts testMainParts, reason string) {
+	names := testMain.Type.Params.List[0].Names
+	if len(names) == 0 || names[0].Name == "_" {
+		return parts, "it never calls m.Run()"
+	}
+
+	m := names[0].Name
+	osName, _ := importedName(r.rootNode, "os", "os")
+	flagName, _ := importedName(r.rootNode, "flag", "flag")
+
+	statements := testMain.Body.List
+	runIndex, code := -1, ""
+	for index, statement := range statements {
+		if name, ok := runStatement(statement, m, osName); ok {
+			runIndex, code = index, name
+			break
+		}
+	}
+
+	if runIndex < 0 {
+		return parts, "it does not call m.Run() on its own, or as os.Exit(m.Run())"
+	}
+
+	if countUnqualifiedIdentifiers(testMain.Body, m) != 1 {
+		return parts, "it uses m for more than calling m.Run()"
+	}
+
+	parts.after = statements[runIndex+1:]
+	if code != "" {
+		last := len(parts.after) - 1
+		if last < 0 || !isExitWith(parts.after[last], osName, code) || countUnqualifiedIdentifiers(testMain.Body, code) != 2 {
+			return parts, "it uses the result of m.Run() for more than passing it to os.Exit"
+		}
+		parts.after = parts.after[:last]
+	}
+
+	for _, statement := range statements[:runIndex] {
+		if reason := checkStatementBeforeRun(statement); reason != "" {
+			return parts, reason
+		}
+
+		switch statement := statement.(type) {
+		case *ast.DeferStmt:
+			if r.target.hasCleanupAndHelper {
+				parts.before = append(parts.before, deferCleanupFor(statement))
+			} else {
+				parts.deferred = append(parts.deferred, statement)
+			}
+			continue
+
+		case *ast.ExprStmt:
+			if _, ok := selectorCallArgs(statement.X, flagName, "Parse"); ok {
+				continue
+			}
+
+			if isFlagCall(statement.X, flagName) {
+				parts.flags = append(parts.flags, statement)
+				continue
+			}
+
+		case *ast.AssignStmt:
+			if len(statement.Rhs) != 1 || !isFlagCall(statement.Rhs[0], flagName) {
+				break
+			}
+
+			if statement.Tok == token.DEFINE {
+				return parts, "it declares a flag with :=, which cannot move into an init func"
+			}
+			parts.flags = append(parts.flags, statement)
+			continue
+		}
+
+		parts.before = append(parts.before, statement)
+	}
+	return parts, ""
+}
+
+/*
+ * Whether the statement runs the tests, returning the name of the variable
+ * holding the exit code when there is one, eg: code in code := m.Run()
+ */
+func runStatement(statement ast.Stmt, m, osName string) (code string, ok bool) {
+	switch statement := statement.(type) {
+	case *ast.ExprStmt:
+		if isRunCall(statement.X, m) {
+			return "", true
+		}
+
+		args, ok := selectorCallArgs(statement.X, osName, "Exit")
+		return "", ok && len(args) == 1 && isRunCall(args[0], m)
+
+	case *ast.AssignStmt:
+		if len(statement.Lhs) != 1 || len(statement.Rhs) != 1 || !isRunCall(statement.Rhs[0], m) {
+			return "", false
+		}
+
+		ident, ok := statement.Lhs[0].(*ast.Ident)
+		if !ok || ident.Name == "_" {
+			return "", ok
+		}
+		return ident.Name, true
+	}
+	return "", false
+}
+
+/*
+ * eg: m.Run()
+ */
+func isRunCall(expr ast.Expr, m string) bool {
+	args, ok := selectorCallArgs(expr, m, "Run")
+	return ok && len(args) == 0
+}
+
+/*
+ * eg: os.Exit(code)
+ */
+func isExitWith(statement ast.Stmt, osName, code string) bool {
+	exprStmt, ok := statement.(*ast.ExprStmt)
+	if !ok {
+		return false
+	}
+
+	args, ok := selectorCallArgs(exprStmt.X, osName, "Exit")
+	if !ok || len(args) != 1 {
+		return false
+	}
+
+	ident, ok := args[0].(*ast.Ident)
+	return ok && ident.Name == code
+}
+
+/*
+ * eg: flag.StringVar(&addr, "addr", "", "the address to test against")
+ */
+func isFlagCall(expr ast.Expr, flagName string) bool {
+	callExpr, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+
+	selectorExpr, ok := callExpr.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+
+	ident, ok := selectorExpr.X.(*ast.Ident)
+	return ok && ident.Name == flagName
+}
+
+/*
+ * A BeforeSuite cannot return early to skip the tests, nor defer calls
+ * until the tests have run, the way TestMain can before calling m.Run()
+ */
+func checkStatementBeforeRun(statement ast.Stmt) (reason string) {
+	ast.Inspect(statement, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			reason = "it returns before calling m.Run()"
+		case *ast.DeferStmt:
+			if node != statement {
+				reason = "it defers calls inside a block"
+			}
+		}
+		return reason == ""
+	})
+	return reason
+}
+
+/*
+ * eg: defer db.Close() becomes DeferCleanup(db.Close)
+ */
+func deferCleanupFor(deferStmt *ast.DeferStmt) ast.Stmt {
+	call := deferStmt.Call
+	args := append([]ast.Expr{call.Fun}, call.Args...)
+	if call.Ellipsis.IsValid() {
+		// eg: defer cleanUp(dirs...) cannot pass its args on as they are
+		funcType := &ast.FuncType{Params: &ast.FieldList{}}
+		body := &ast.BlockStmt{List: []ast.Stmt{&ast.ExprStmt{X: call}}}
+		args = []ast.Expr{&ast.FuncLit{Type: funcType, Body: body}}
+	}
+
+	cleanup := &ast.Ident{NamePos: deferStmt.Defer, Name: "DeferCleanup"}
+	return &ast.ExprStmt{X: &ast.CallExpr{Fun: cleanup, Args: args}}
+}
+
+/*
+ * Variables the BeforeSuite declares that the AfterSuite uses are declared
+ * at the top level instead, eg: db := openDB() becomes `var db *DB` and
+ * db = openDB() in the BeforeSuite. ok is false (reporting why) when the
+ * type of one of them cannot be inferred.
+ */
+func (r *fileRewriter) shareDeclarations(before, after []ast.Stmt) (declarations, statements []ast.Stmt, ok bool) {
+	afterBlock := &ast.BlockStmt{List: after}
+	for _, statement := range before {
+		shared := false
+		for _, name := range declaredNames(statement) {
+			if countUnqualifiedIdentifiers(afterBlock, name) > 0 {
+				shared = true
+			}
+		}
+
+		if !shared {
+			statements = append(statements, statement)
+			continue
+		}
+
+		hoisted, assignments, ok := r.hoistDeclarations([]ast.Stmt{statement}, hoistingIntoSuiteHooks)
+		if !ok {
+			return nil, nil, false
+		}
+		declarations = append(declarations, hoisted...)
+		statements = append(statements, assignments...)
+	}
+	return declarations, statements, true
+}
+
+/*
+ * The variables a statement declares, eg: db and err in db, err := openDB()
+ */
+func declaredNames(statement ast.Stmt) (names []string) {
+	switch statement := statement.(type) {
+	case *ast.AssignStmt:
+		if statement.Tok != token.DEFINE {
+			return nil
+		}
+
+		for _, lhs := range statement.Lhs {
+			if ident, ok := lhs.(*ast.Ident); ok && ident.Name != "_" {
+				names = append(names, ident.Name)
+			}
+		}
+
+	case *ast.DeclStmt:
+		genDecl, ok := statement.Decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.VAR {
+			return nil
+		}
+
+		for _, spec := range genDecl.Specs {
+			for _, name := range spec.(*ast.ValueSpec).Names {
+				names = append(names, name.Name)
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,333 @@
+package converter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"io/ioutil"
+	"os"
+)
+
+/*
+ * Given a file path, reads the file and converts it with ConvertSource,
+ * returning the file's new contents alongside its original contents,
+ * ready to be written or diffed.
+ */
+func (c *Converter) rewriteTestsInFile(pathToFile string, data TemplateData) (change FileChange, diagnostics []Diagnostic, err error) {
+	original, err := ioutil.ReadFile(pathToFile)
+	if err != nil {
+		return change, nil, fmt.Errorf("Error reading test file '%s':\n%s\n", pathToFile, err.Error())
+	}
+
+	fileInfo, err := os.Stat(pathToFile)
+	if err != nil {
+		return change, nil, fmt.Errorf("Error stat'ing file: %s\n", pathToFile)
+	}
+
+	converted, diagnostics, err := c.convertSource(pathToFile, original, data)
+	if err != nil {
+		return change, diagnostics, err
+	}
+
+	change = FileChange{
+		Path:      pathToFile,
+		Original:  original,
+		Converted: converted,
+		Mode:      fileInfo.Mode(),
+	}
+	return change, diagnostics, nil
+}
+
+/*
+ * Rewrites any tests in the Ginkgo format.
+ * First, we find the file's top level Describe, or declare one: in a top
+ * level init func for ginkgo v1, as a top level var for v2.
+ * Then any gocheck suites registered in the file become Describes inside it,
+ * TestMain becomes a BeforeSuite and an AfterSuite,
+ * and the test functions to rewrite are inserted as It statements inside the Describe,
+ * followed by the benchmarks and examples (when asked to).
+ * If none of them could be converted, the file is left as it is.
+ * Otherwise, we walk the rest of the file, replacing other usages of *testing.T
+ * Finally, testify calls (when asked to), error checks and other guards that
+ * fail the test become gomega assertions, the imports are updated to match
+ * what the file refers to, and the code that was created is laid out next
+ * to the code it came from.
+ */
+func (r *fileRewriter) rewriteTests() (converted bool, err error) {
+	rootNode := r.rootNode
+	backend := r.target.backend
+
+	describeBlock, existing := findTopLevelDescribe(rootNode)
+	if !existing {
+		describeBlock, err = r.createDescribeBlock()
+		if err != nil {
+			return false, err
+		}
+	}
+
+	block, err := blockStatementFromDescribe(describeBlock)
+	if err != nil {
+		return false, err
+	}
+	specs, testMain := len(block.List), findTestMain(rootNode)
+
+	err = r.convertGocheckSuites(describeBlock)
+	if err != nil {
+		return false, err
+	}
+
+	err = r.convertTestMain()
+	if err != nil {
+		return false, err
+	}
+
+	for _, testFunc := range findTestFuncs(rootNode) {
+		if r.target.hasCleanupAndHelper {
+			replaceCleanupAndHelperCalls(testFunc.Body, namedTestingTArg(testFunc))
+		}
+
+		spec, testingT := r.createSpecForTestFunc(testFunc)
+		if spec == nil {
+			continue
+		}
+
+		err = rewriteTestFuncAsItStatement(testFunc, spec, testingT, rootNode, describeBlock, backend)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if r.converter.options.Benchmarks {
+		err = r.convertBenchmarks(describeBlock)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if r.converter.options.Examples {
+		err = r.convertExamples(describeBlock)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if len(block.List) == specs && findTestMain(rootNode) == testMain {
+		return false, nil
+	}
+
+	if existing {
+		// the container closes after its new specs, wherever they came from
+		block.Rbrace = token.NoPos
+	}
+
+	if !existing && r.target.useInitFunc {
+		topLevelInitFunc := createInitBlock()
+		topLevelInitFunc.Body.List = append(topLevelInitFunc.Body.List, describeBlock)
+		rootNode.Decls = append(rootNode.Decls, topLevelInitFunc)
+	} else if !existing {
+		rootNode.Decls = append(rootNode.Decls, createTopLevelContainer(describeBlock))
+	}
+
+	if r.target.hasCleanupAndHelper {
+		replaceCleanupAndHelperCallsInOtherFuncs(rootNode.Decls)
+	}
+	rewriteOtherFuncsToUseMrT(rootNode.Decls, backend)
+	walkNodesInRootNodeReplacingTestingT(rootNode, backend)
+
+	r.removeUnusedFrameworkImports()
+
+	if r.converter.options.Testify {
+		err = r.rewriteTestifyCalls(describeBlock)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	r.rewriteAssertions(describeBlock)
+	r.updateImports()
+	r.layOutGeneratedCode()
+	return true, nil
+}
+
+/*
+ * Removes the imports of the test frameworks whose tests were converted,
+ * once nothing refers to them anymore
+ */
+func (r *fileRewriter) removeUnusedFrameworkImports() {
+	if suitePackage, ok := importedName(r.rootNode, testifySuiteImportPath, "suite"); ok {
+		removeImportIfUnused(r.rootNode, suitePackage, testifySuiteImportPath)
+	}
+
+	if gocheckPackage, ok := importedName(r.rootNode, gocheckImportPath, "check"); ok {
+		removeFrameworkImportIfUnused(r.rootNode, gocheckPackage, gocheckImportPath, gocheckNames)
+	}
+
+	if !r.converter.options.GoConvey {
+		return
+	}
+
+	if conveyPackage, ok := importedName(r.rootNode, goConveyImportPath, "convey"); ok {
+		removeFrameworkImportIfUnused(r.rootNode, conveyPackage, goConveyImportPath, goConveyNames)
+	}
+}
+
+/*
+ * Imports what the converted code refers to: ginkgo, gomega once an
+ * assertion was created, the table extension for ginkgo v1 and the package
+ * replacing *testing.T (when something still uses it). Then removes the
+ * imports that converting the tests left unused, eg: testing.
+ */
+func (r *fileRewriter) updateImports() {
+	addImport(r.rootNode, ".", r.target.importPath)
+	if r.usesTables && r.target.tableImportPath != "" {
+		addImport(r.rootNode, ".", r.target.tableImportPath)
+	}
+
+	if r.usesGomega {
+		addImport(r.rootNode, ".", gomegaImportPath)
+	}
+
+	// the helpers using *testing.T may be all that still uses its replacement
+	backend := r.target.backend
+	if backend.ImportPath != "" {
+		addImportIfUsed(r.rootNode, backend.PackageName, backend.ImportPath)
+		removeImportIfUnused(r.rootNode, backend.PackageName, backend.ImportPath)
+	}
+
+	for _, path := range []string{"testing", "reflect", "errors"} {
+		packageName, ok := importedName(r.rootNode, path, path)
+		if ok && packageName != "." && packageName != "_" {
+			removeImportIfUnused(r.rootNode, packageName, path)
+		}
+	}
+}
+
+/*
+ * Given a test func named TestDoesSomethingNeat, and the spec it was rewritten
+ * as (eg: It("does something neat", func() { __test_body_here__ })), adds the
+ * spec to the Describe's list of statements and removes the test func.
+ * testingT is the name the spec uses for the test's *testing.T.
+ */
+func rewriteTestFuncAsItStatement(testFunc *ast.FuncDecl, spec ast.Stmt, testingT string, rootNode *ast.File, describe *ast.ExprStmt, backend TestingTBackend) error {
+	var funcIndex int = -1
+	for index, child := range rootNode.Decls {
+		if child == testFunc {
+			funcIndex = index
+			break
+		}
+	}
+
+	if funcIndex < 0 {
+		return fmt.Errorf("Assert failed: Error finding index for test node %s\n", testFunc.Name.Name)
+	}
+
+	block, err := blockStatementFromDescribe(describe)
+	if err != nil {
+		return err
+	}
+
+	positionSpecAt(spec, testFunc.Pos(), testFunc.Body)
+	block.List = insertInFileOrder(block.List, spec)
+	replaceTestingTsWithMrT(spec, testingT, backend)
+
+	// remove the old test func from the root node's declarations
+	rootNode.Decls = append(rootNode.Decls[:funcIndex], rootNode.Decls[funcIndex+1:]...)
+	return nil
+}
+
+/*
+ * Adds a spec after the statements that come before it in the file, eg: a
+ * benchmark's It goes before the Its of the tests declared after it, so
+ * that the blank lines between the funcs are kept between their specs
+ */
+func insertInFileOrder(statements []ast.Stmt, spec ast.Stmt) []ast.Stmt {
+	index := len(statements)
+	for index > 0 && spec.Pos().IsValid() && statements[index-1].Pos() > spec.Pos() {
+		index--
+	}
+	return append(statements[:index], append([]ast.Stmt{spec}, statements[index:]...)...)
+}
+
+/*
+ * walks nodes inside of a test func's statements and replaces the usage of
+ * it's named *testing.T param with GinkgoT's
+ */
+func replaceTestingTsWithMrT(statementsBlock ast.Node, testingT string, backend TestingTBackend) {
+	ast.Inspect(statementsBlock, func(node ast.Node) bool {
+		if node == nil {
+			return false
+		}
+
+		keyValueExpr, ok := node.(*ast.KeyValueExpr)
+		if ok {
+			replaceNamedTestingTsInKeyValueExpression(keyValueExpr, testingT, backend)
+			return true
+		}
+
+		funcLiteral, ok := node.(*ast.FuncLit)
+		if ok {
+			replaceTypeDeclTestingTsInFuncLiteral(funcLiteral, backend)
+			return true
+		}
+
+		callExpr, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		replaceTestingTsInArgsLists(callExpr, testingT, backend)
+
+		funCall, ok := callExpr.Fun.(*ast.SelectorExpr)
+		if ok {
+			replaceTestingTsMethodCalls(funCall, testingT, backend)
+		}
+
+		return true
+	})
+}
+
+/*
+ * rewrite t.Fail() or any other *testing.T method by replacing with T().Fail()
+ * This function receives a selector expression (eg: t.Fail()) and
+ * the name of the *testing.T param from the function declaration. Rewrites the
+ * selector expression in place if the target was a *testing.T
+ */
+func replaceTestingTsMethodCalls(selectorExpr *ast.SelectorExpr, testingT string, backend TestingTBackend) {
+	ident, ok := selectorExpr.X.(*ast.Ident)
+	if !ok {
+		return
+	}
+
+	if ident.Name == testingT {
+		selectorExpr.X = backend.newTFromIdent(ident)
+	}
+}
+
+/*
+ * replaces usages of a named *testing.T param inside of a call expression
+ * with a new GinkgoT object
+ */
+func replaceTestingTsInArgsLists(callExpr *ast.CallExpr, testingT string, backend TestingTBackend) {
+	for index, arg := range callExpr.Args {
+		ident, ok := arg.(*ast.Ident)
+		if !ok {
+			continue
+		}
+
+		if ident.Name == testingT {
+			callExpr.Args[index] = backend.newTFromIdent(ident)
+		}
+	}
+}
+
+/*
+ * Creates the container the file's Its are added to, from the converter's
+ * spec template when it has one.
+ */
+func (r *fileRewriter) createDescribeBlock() (*ast.ExprStmt, error) {
+	specTemplate := r.converter.options.SpecTemplate
+	if specTemplate == "" {
+		return createDescribeBlock(), nil
+	}
+
+	return createDescribeBlockFromTemplate(r.fileSet, specTemplate, r.data)
+}
@@ -0,0 +1,274 @@
+package converter
+
+import (
+	"go/ast"
+	"go/token"
+	"strconv"
+)
+
+var testifyPackages = map[string]string{
+	"github.com/stretchr/testify/assert":  "assert",
+	"github.com/stretchr/testify/require": "require",
+}
+
+/*
+ * How a testify assertion maps onto gomega: the number of args it takes
+ * (after the T, before any message args) and the expectation they become
+ */
+type testifyMatcher struct {
+	args  int
+	build func(args []ast.Expr) (actual ast.Expr, negated bool, matcher ast.Expr)
+}
+
+func testifyExpectation(actual int, negated bool, matcher string, expected ...int) func([]ast.Expr) (ast.Expr, bool, ast.Expr) {
+	return func(args []ast.Expr) (ast.Expr, bool, ast.Expr) {
+		matcherArgs := []ast.Expr{}
+		for _, index := range expected {
+			matcherArgs = append(matcherArgs, args[index])
+		}
+		return args[actual], negated, createMatcher(matcher, matcherArgs...)
+	}
+}
+
+func testifyNumericExpectation(comparator string) func([]ast.Expr) (ast.Expr, bool, ast.Expr) {
+	return func(args []ast.Expr) (ast.Expr, bool, ast.Expr) {
+		return args[0], false, createNumericMatcher(comparator, args[1])
+	}
+}
+
+var testifyMatchers = map[string]testifyMatcher{
+	"Equal":          {2, testifyExpectation(1, false, "Equal", 0)},
+	"Exactly":        {2, testifyExpectation(1, false, "Equal", 0)},
+	"NotEqual":       {2, testifyExpectation(1, true, "Equal", 0)},
+	"EqualValues":    {2, testifyExpectation(1, false, "BeEquivalentTo", 0)},
+	"Nil":            {1, testifyExpectation(0, false, "BeNil")},
+	"NotNil":         {1, testifyExpectation(0, true, "BeNil")},
+	"NoError":        {1, testifyExpectation(0, true, "HaveOccurred")},
+	"Error":          {1, testifyExpectation(0, false, "HaveOccurred")},
+	"EqualError":     {2, testifyExpectation(0, false, "MatchError", 1)},
+	"ErrorIs":        {2, testifyExpectation(0, false, "MatchError", 1)},
+	"True":           {1, testifyExpectation(0, false, "BeTrue")},
+	"False":          {1, testifyExpectation(0, false, "BeFalse")},
+	"Len":            {2, testifyExpectation(0, false, "HaveLen", 1)},
+	"ElementsMatch":  {2, testifyExpectation(0, false, "ConsistOf", 1)},
+	"Empty":          {1, testifyExpectation(0, false, "BeEmpty")},
+	"NotEmpty":       {1, testifyExpectation(0, true, "BeEmpty")},
+	"Zero":           {1, testifyExpectation(0, false, "BeZero")},
+	"NotZero":        {1, testifyExpectation(0, true, "BeZero")},
+	"IsType":         {2, testifyExpectation(1, false, "BeAssignableToTypeOf", 0)},
+	"JSONEq":         {2, testifyExpectation(1, false, "MatchJSON", 0)},
+	"Panics":         {1, testifyExpectation(0, false, "Panic")},
+	"NotPanics":      {1, testifyExpectation(0, true, "Panic")},
+	"Greater":        {2, testifyNumericExpectation(">")},
+	"GreaterOrEqual": {2, testifyNumericExpectation(">=")},
+	"Less":           {2, testifyNumericExpectation("<")},
+	"LessOrEqual":    {2, testifyNumericExpectation("<=")},
+	"Contains":       {2, testifyContains(false)},
+	"NotContains":    {2, testifyContains(true)},
+	"ErrorContains":  {2, testifyErrorContains},
+	"InDelta":        {3, testifyInDelta},
+	"Regexp":         {2, testifyRegexp},
+}
+
+/*
+ * testify's Contains works on strings, slices and maps. Without type
+ * checking, a string literal is looked for as a substring, anything else
+ * as an element.
+ */
+func testifyContains(negated bool) func([]ast.Expr) (ast.Expr, bool, ast.Expr) {
+	return func(args []ast.Expr) (ast.Expr, bool, ast.Expr) {
+		if literal, ok := args[1].(*ast.BasicLit); ok && literal.Kind == token.STRING {
+			return args[0], negated, createMatcher("ContainSubstring", args[1])
+		}
+		return args[0], negated, createMatcher("ContainElement", args[1])
+	}
+}
+
+func testifyErrorContains(args []ast.Expr) (ast.Expr, bool, ast.Expr) {
+	return args[0], false, createMatcher("MatchError", createMatcher("ContainSubstring", args[1]))
+}
+
+func testifyInDelta(args []ast.Expr) (ast.Expr, bool, ast.Expr) {
+	comparator := &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote("~")}
+	return args[1], false, createMatcher("BeNumerically", comparator, args[0], args[2])
+}
+
+func testifyRegexp(args []ast.Expr) (ast.Expr, bool, ast.Expr) {
+	return args[1], false, createMatcher("MatchRegexp", args[0])
+}
+
+/*
+ * Replaces calls to testify's assert and require packages with gomega.
+ * Every gomega assertion stops the spec when it fails, which is what
+ * require does. An assert call lets the test go on instead, so converting
+ * one followed by more statements is reported. Calls that have no gomega
+ * equivalent, or whose result is used, are reported and left alone.
+ */
+func (r *fileRewriter) rewriteTestifyCalls(root ast.Node) error {
+	packages := r.testifyPackageNames()
+	if len(packages) == 0 {
+		return nil
+	}
+
+	reported := map[*ast.CallExpr]bool{}
+	ast.Inspect(root, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.BlockStmt:
+			r.rewriteTestifyCallsInStatements(node.List, packages, reported)
+		case *ast.CaseClause:
+			r.rewriteTestifyCallsInStatements(node.Body, packages, reported)
+		case *ast.CommClause:
+			r.rewriteTestifyCallsInStatements(node.Body, packages, reported)
+		case *ast.CallExpr:
+			if name, function, ok := testifyCall(node, packages); ok && !reported[node] {
+				r.report(node.Pos(), "the result of %s.%s is used, so it was not converted to gomega", name, function)
+			}
+		}
+		return true
+	})
+
+	for path, name := range testifyPackages {
+		importName, ok := packages[name]
+		if !ok {
+			continue
+		}
+
+		removeImportIfUnused(r.rootNode, importName, path)
+	}
+	return nil
+}
+
+func (r *fileRewriter) rewriteTestifyCallsInStatements(statements []ast.Stmt, packages map[string]string, reported map[*ast.CallExpr]bool) {
+	stricter, stricterFunction := token.NoPos, ""
+	for index, statement := range statements {
+		exprStmt, ok := statement.(*ast.ExprStmt)
+		if !ok {
+			continue
+		}
+
+		callExpr, ok := exprStmt.X.(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+
+		name, function, ok := testifyCall(callExpr, packages)
+		if !ok {
+			continue
+		}
+
+		var expectation *ast.ExprStmt
+		ok = len(callExpr.Args) > 0
+		if ok {
+			expectation, ok = testifyAssertionAsExpectation(callExpr.Pos(), function, callExpr.Args[1:])
+		}
+
+		if !ok {
+			r.report(callExpr.Pos(), "%s.%s has no gomega equivalent, so it was not converted", name, function)
+			reported[callExpr] = true
+			continue
+		}
+
+		if name == "assert" && index < len(statements)-1 && !stricter.IsValid() {
+			stricter, stricterFunction = callExpr.Pos(), function
+		}
+
+		statements[index] = expectation
+		r.usesGomega = true
+	}
+
+	// once per block, rather than for each of its assertions
+	if stricter.IsValid() {
+		r.report(stricter, "assert.%s and the assert calls after it now end the spec when they fail, where testify would carry on with the test", stricterFunction)
+	}
+}
+
+/*
+ * args are the assertion's args after the T, eg: for assert.Equal(t, 1, x)
+ * they are 1 and x. Suite methods (eg: s.Equal(1, x)) do not take a T.
+ */
+func testifyAssertionAsExpectation(pos token.Pos, function string, args []ast.Expr) (*ast.ExprStmt, bool) {
+	if function == "Eventually" || function == "Never" {
+		return testifyPollingAsExpectation(pos, function, args)
+	}
+
+	mapping, ok := testifyMatchers[function]
+	if !ok || len(args) < mapping.args {
+		return nil, false
+	}
+
+	actual, negated, matcher := mapping.build(args[:mapping.args])
+	return createExpectation(pos, actual, negated, matcher, args[mapping.args:]), true
+}
+
+/*
+ * assert.Eventually(t, condition, waitFor, tick) becomes
+ * Eventually(condition, waitFor, tick).Should(BeTrue()), and
+ * assert.Never becomes Consistently(...).Should(BeFalse())
+ */
+func testifyPollingAsExpectation(pos token.Pos, function string, args []ast.Expr) (*ast.ExprStmt, bool) {
+	if len(args) < 3 {
+		return nil, false
+	}
+
+	poll, matcher := "Eventually", "BeTrue"
+	if function == "Never" {
+		poll, matcher = "Consistently", "BeFalse"
+	}
+
+	polling := &ast.CallExpr{
+		Fun:  &ast.Ident{NamePos: pos, Name: poll},
+		Args: args[:3],
+	}
+	should := &ast.CallExpr{
+		Fun:  &ast.SelectorExpr{X: polling, Sel: &ast.Ident{Name: "Should"}},
+		Args: append([]ast.Expr{createMatcher(matcher)}, args[3:]...),
+	}
+	return &ast.ExprStmt{X: should}, true
+}
+
+/*
+ * eg: assert.Equal(...) returns "assert", "Equal"
+ */
+func testifyCall(callExpr *ast.CallExpr, packages map[string]string) (name, function string, ok bool) {
+	selectorExpr, ok := callExpr.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return "", "", false
+	}
+
+	ident, ok := selectorExpr.X.(*ast.Ident)
+	if !ok {
+		return "", "", false
+	}
+
+	for packageName, importName := range packages {
+		if ident.Name == importName {
+			return packageName, selectorExpr.Sel.Name, true
+		}
+	}
+	return "", "", false
+}
+
+/*
+ * The names testify's assert and require packages are imported as in this file
+ */
+func (r *fileRewriter) testifyPackageNames() map[string]string {
+	packages := map[string]string{}
+	for _, importSpec := range r.rootNode.Imports {
+		path, err := strconv.Unquote(importSpec.Path.Value)
+		if err != nil {
+			continue
+		}
+
+		name, ok := testifyPackages[path]
+		if !ok {
+			continue
+		}
+
+		importName := name
+		if importSpec.Name != nil {
+			importName = importSpec.Name.Name
+		}
+		packages[name] = importName
+	}
+	return packages
+}
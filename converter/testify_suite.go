@@ -0,0 +1,270 @@
+package converter
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+)
+
+const testifySuiteImportPath = "github.com/stretchr/testify/suite"
+
+var testifySuiteStyle = suiteStyle{
+	hooks: map[string]string{
+		"SetupSuite":    "BeforeAll",
+		"SetupTest":     "BeforeEach",
+		"TearDownTest":  "AfterEach",
+		"TearDownSuite": "AfterAll",
+	},
+	params: func(params *ast.FieldList) bool {
+		return params.NumFields() == 0
+	},
+	unsupported: func(name string) bool {
+		switch name {
+		case "BeforeTest", "AfterTest", "SetupSubTest", "TearDownSubTest", "HandleStats":
+			return true
+		}
+		return false
+	},
+}
+
+/*
+ * Converts a test that only runs a testify suite, eg:
+ *   func TestDatabaseSuite(t *testing.T) { suite.Run(t, new(DatabaseSuite)) }
+ * into a Describe holding the suite. The suite's assertions (eg: s.Equal,
+ * s.Require().NoError) become gomega assertions.
+ * runsSuite is false when the test does not run a suite. When the suite
+ * cannot be converted (reporting why), describe is nil: suite.Run needs a
+ * real *testing.T, so the test must stay as it is.
+ */
+func (r *fileRewriter) createDescribeForTestifySuite(testFunc *ast.FuncDecl, name *ast.BasicLit) (describe *ast.ExprStmt, runsSuite bool) {
+	suitePackage, ok := importedName(r.rootNode, testifySuiteImportPath, "suite")
+	if !ok {
+		return nil, false
+	}
+
+	run, ok := suiteRunCall(testFunc.Body, suitePackage)
+	if !ok {
+		return nil, false
+	}
+
+	if len(testFunc.Body.List) != 1 || len(run.Args) != 2 {
+		r.report(testFunc.Pos(), "a test running a testify suite must only call %s.Run(t, new(Suite)); left the test as it is", suitePackage)
+		return nil, true
+	}
+
+	testSuite, reason := r.findTestifySuite(run.Args[1], suitePackage)
+	if reason != "" {
+		r.report(testFunc.Pos(), "could not convert the testify suite: %s; left the test as it is", reason)
+		return nil, true
+	}
+
+	methods := []suiteMethod{}
+	for _, decl := range testSuite.methods {
+		method, reason := r.checkTestifySuiteMethod(testSuite, decl)
+		if reason != "" {
+			r.report(decl.Pos(), "could not convert %s.%s: %s; left the test running the suite as it is", testSuite.typeSpec.Name.Name, decl.Name.Name, reason)
+			return nil, true
+		}
+		methods = append(methods, method)
+	}
+
+	for _, method := range methods {
+		r.rewriteSuiteMethod(testSuite, method)
+		r.replaceSuiteTCalls(method)
+	}
+	return r.createSuiteDescribe(testSuite, name), true
+}
+
+/*
+ * eg: suite.Run(t, new(DatabaseSuite))
+ */
+func suiteRunCall(body *ast.BlockStmt, suitePackage string) (*ast.CallExpr, bool) {
+	var run *ast.CallExpr
+	ast.Inspect(body, func(node ast.Node) bool {
+		callExpr, ok := node.(*ast.CallExpr)
+		if ok && run == nil {
+			if _, ok := selectorCallArgs(callExpr, suitePackage, "Run"); ok {
+				run = callExpr
+			}
+		}
+		return run == nil
+	})
+	return run, run != nil
+}
+
+/*
+ * Finds the suite that is run, which must embed suite.Suite and nothing else
+ */
+func (r *fileRewriter) findTestifySuite(value ast.Expr, suitePackage string) (testSuite xunitSuite, reason string) {
+	typeName, values, reason := suiteValue(value)
+	if reason != "" {
+		return testSuite, reason
+	}
+
+	testSuite, reason = r.findSuite(typeName, testifySuiteStyle)
+	if reason != "" {
+		return testSuite, reason
+	}
+	testSuite.values = values
+
+	embedsSuite := false
+	for _, embedded := range testSuite.embedded {
+		selectorExpr, ok := embedded.(*ast.SelectorExpr)
+		if !ok || selectorExpr.Sel.Name != "Suite" {
+			return testSuite, fmt.Sprintf("%s embeds a type other than %s.Suite", typeName, suitePackage)
+		}
+
+		ident, ok := selectorExpr.X.(*ast.Ident)
+		if !ok || ident.Name != suitePackage {
+			return testSuite, fmt.Sprintf("%s embeds a type other than %s.Suite", typeName, suitePackage)
+		}
+		embedsSuite = true
+	}
+
+	if !embedsSuite {
+		return testSuite, fmt.Sprintf("%s does not embed %s.Suite", typeName, suitePackage)
+	}
+	return testSuite, ""
+}
+
+/*
+ * Besides the suite's fields and helpers, a testify suite's methods can use
+ * T() and assertions that have a gomega equivalent. Assertions may also be
+ * made through a variable holding s.Require() or s.Assert(),
+ * eg: require := s.Require()
+ */
+func (r *fileRewriter) checkTestifySuiteMethod(testSuite xunitSuite, decl *ast.FuncDecl) (method suiteMethod, reason string) {
+	method, reason = newSuiteMethod(testSuite, decl)
+	if reason != "" || method.receiver == "" {
+		return method, reason
+	}
+
+	names := map[string]bool{method.receiver: true}
+	ast.Inspect(decl.Body, func(node ast.Node) bool {
+		assignStmt, ok := node.(*ast.AssignStmt)
+		if !ok || assignStmt.Tok != token.DEFINE || len(assignStmt.Lhs) != 1 || len(assignStmt.Rhs) != 1 {
+			return true
+		}
+
+		alias, ok := assignStmt.Lhs[0].(*ast.Ident)
+		if receiver, ok2 := assertionsOfSuite(assignStmt.Rhs[0], method.receiver); ok && ok2 {
+			method.replacements[assignStmt] = nil
+			names[alias.Name] = true
+			method.converted[receiver] = true
+			method.converted[alias] = true
+		}
+		return true
+	})
+
+	ast.Inspect(decl.Body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.ExprStmt:
+			callExpr, ok := node.X.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+
+			receiver, function, ok := suiteAssertionCall(callExpr, method.receiver, names, testSuite.members)
+			if !ok {
+				return true
+			}
+
+			expectation, ok := testifyAssertionAsExpectation(callExpr.Pos(), function, callExpr.Args)
+			if !ok {
+				if reason == "" {
+					reason = fmt.Sprintf("%s.%s has no gomega equivalent", receiver.Name, function)
+				}
+				return true
+			}
+			method.replacements[node] = expectation
+			method.converted[receiver] = true
+
+		case *ast.CallExpr:
+			if ident, ok := suiteTCall(node, method.receiver); ok {
+				method.converted[ident] = true
+			}
+		}
+		return true
+	})
+
+	if reason != "" {
+		return method, reason
+	}
+	return method, method.unconvertedUse(names)
+}
+
+/*
+ * eg: s.Require() or s.Assert(), returning s
+ */
+func assertionsOfSuite(expr ast.Expr, receiver string) (*ast.Ident, bool) {
+	callExpr, ok := expr.(*ast.CallExpr)
+	if !ok || len(callExpr.Args) != 0 {
+		return nil, false
+	}
+
+	selectorExpr, ok := callExpr.Fun.(*ast.SelectorExpr)
+	if !ok || (selectorExpr.Sel.Name != "Require" && selectorExpr.Sel.Name != "Assert") {
+		return nil, false
+	}
+
+	ident, ok := selectorExpr.X.(*ast.Ident)
+	return ident, ok && ident.Name == receiver
+}
+
+/*
+ * Recognises the assertions a suite makes, eg: s.Equal(...),
+ * s.Require().NoError(...) or require.Len(...) given require := s.Require(),
+ * returning the receiver (or variable) they are made on
+ */
+func suiteAssertionCall(callExpr *ast.CallExpr, receiver string, names, members map[string]bool) (*ast.Ident, string, bool) {
+	selectorExpr, ok := callExpr.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return nil, "", false
+	}
+
+	function := selectorExpr.Sel.Name
+	if ident, ok := assertionsOfSuite(selectorExpr.X, receiver); ok {
+		return ident, function, true
+	}
+
+	ident, ok := selectorExpr.X.(*ast.Ident)
+	if !ok || !names[ident.Name] {
+		return nil, "", false
+	}
+
+	if ident.Name == receiver && (members[function] || function == "T" || function == "Run") {
+		return nil, "", false
+	}
+	return ident, function, true
+}
+
+/*
+ * eg: s.T(), returning s
+ */
+func suiteTCall(callExpr *ast.CallExpr, receiver string) (*ast.Ident, bool) {
+	selectorExpr, ok := callExpr.Fun.(*ast.SelectorExpr)
+	if !ok || selectorExpr.Sel.Name != "T" || len(callExpr.Args) != 0 {
+		return nil, false
+	}
+
+	ident, ok := selectorExpr.X.(*ast.Ident)
+	return ident, ok && ident.Name == receiver
+}
+
+/*
+ * s.T() becomes whatever replaces *testing.T, eg: mr.T()
+ */
+func (r *fileRewriter) replaceSuiteTCalls(method suiteMethod) {
+	if method.receiver == "" {
+		return
+	}
+
+	replaceExpressions(method.decl.Body, func(expr ast.Expr) ast.Expr {
+		if callExpr, ok := expr.(*ast.CallExpr); ok {
+			if ident, ok := suiteTCall(callExpr, method.receiver); ok {
+				return r.target.backend.newTFromIdent(ident)
+			}
+		}
+		return expr
+	})
+}
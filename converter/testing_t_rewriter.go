@@ -0,0 +1,225 @@
+package converter
+
+import (
+	"go/ast"
+)
+
+/*
+ * Rewrites any other top level funcs that receive a *testing.T param.
+ * Tests that were left as they are keep theirs, since go test runs them.
+ */
+func rewriteOtherFuncsToUseMrT(declarations []ast.Decl, backend TestingTBackend) {
+	for _, decl := range declarations {
+		decl, ok := decl.(*ast.FuncDecl)
+		if !ok || isTestFunc(decl) {
+			continue
+		}
+
+		for _, param := range decl.Type.Params.List {
+			starExpr, ok := param.Type.(*ast.StarExpr)
+			if !ok {
+				continue
+			}
+
+			selectorExpr, ok := starExpr.X.(*ast.SelectorExpr)
+			if !ok {
+				continue
+			}
+
+			xIdent, ok := selectorExpr.X.(*ast.Ident)
+			if !ok || xIdent.Name != "testing" {
+				continue
+			}
+
+			if selectorExpr.Sel.Name != "T" {
+				continue
+			}
+
+			param.Type = backend.newTestingT()
+		}
+	}
+}
+
+/*
+ * Walks all of the nodes in the file, replacing *testing.T in struct
+ * and func literal nodes. eg:
+ *   type foo struct { *testing.T }
+ *   var bar = func(t *testing.T) { }
+ */
+func walkNodesInRootNodeReplacingTestingT(rootNode *ast.File, backend TestingTBackend) {
+	ast.Inspect(rootNode, func(node ast.Node) bool {
+		if node == nil {
+			return false
+		}
+
+		switch node := node.(type) {
+		case *ast.StructType:
+			replaceTestingTsInStructType(node, backend)
+		case *ast.FuncLit:
+			replaceTypeDeclTestingTsInFuncLiteral(node, backend)
+		}
+
+		return true
+	})
+}
+
+/*
+ * replaces named *testing.T inside a composite literal
+ */
+func replaceNamedTestingTsInKeyValueExpression(kve *ast.KeyValueExpr, testingT string, backend TestingTBackend) {
+	ident, ok := kve.Value.(*ast.Ident)
+	if !ok {
+		return
+	}
+
+	if ident.Name == testingT {
+		kve.Value = backend.newTFromIdent(ident)
+	}
+}
+
+/*
+ * replaces *testing.T params in a func literal with GinkgoT
+ */
+func replaceTypeDeclTestingTsInFuncLiteral(functionLiteral *ast.FuncLit, backend TestingTBackend) {
+	for _, arg := range functionLiteral.Type.Params.List {
+		starExpr, ok := arg.Type.(*ast.StarExpr)
+		if !ok {
+			continue
+		}
+
+		selectorExpr, ok := starExpr.X.(*ast.SelectorExpr)
+		if !ok {
+			continue
+		}
+
+		target, ok := selectorExpr.X.(*ast.Ident)
+		if !ok {
+			continue
+		}
+
+		if target.Name == "testing" && selectorExpr.Sel.Name == "T" {
+			arg.Type = backend.newTestingT()
+		}
+	}
+}
+
+/*
+ * Replaces *testing.T types inside of a struct declaration with a GinkgoT
+ * eg: type foo struct { *testing.T }
+ */
+func replaceTestingTsInStructType(structType *ast.StructType, backend TestingTBackend) {
+	for _, field := range structType.Fields.List {
+		starExpr, ok := field.Type.(*ast.StarExpr)
+		if !ok {
+			continue
+		}
+
+		selectorExpr, ok := starExpr.X.(*ast.SelectorExpr)
+		if !ok {
+			continue
+		}
+
+		xIdent, ok := selectorExpr.X.(*ast.Ident)
+		if !ok {
+			continue
+		}
+
+		if xIdent.Name == "testing" && selectorExpr.Sel.Name == "T" {
+			field.Type = backend.newTestingT()
+		}
+	}
+}
+
+/*
+ * Rewrites t.Cleanup(f) as DeferCleanup(f) and t.Helper() as GinkgoHelper(),
+ * where t is the named *testing.T param of the func being converted
+ */
+func replaceCleanupAndHelperCalls(body *ast.BlockStmt, testingT string) {
+	if body == nil || testingT == "" {
+		return
+	}
+
+	ast.Inspect(body, func(node ast.Node) bool {
+		callExpr, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+
+		selectorExpr, ok := callExpr.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+
+		ident, ok := selectorExpr.X.(*ast.Ident)
+		if !ok || ident.Name != testingT {
+			return true
+		}
+
+		switch selectorExpr.Sel.Name {
+		case "Cleanup":
+			callExpr.Fun = &ast.Ident{NamePos: ident.NamePos, Name: "DeferCleanup"}
+		case "Helper":
+			callExpr.Fun = &ast.Ident{NamePos: ident.NamePos, Name: "GinkgoHelper"}
+		}
+		return true
+	})
+}
+
+/*
+ * Rewrites t.Cleanup and t.Helper in any other top level funcs that
+ * receive a *testing.T param, eg: test helpers
+ */
+func replaceCleanupAndHelperCallsInOtherFuncs(declarations []ast.Decl) {
+	for _, decl := range declarations {
+		decl, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+
+		for _, param := range decl.Type.Params.List {
+			if !isTestingTPointer(param.Type) {
+				continue
+			}
+
+			for _, name := range param.Names {
+				replaceCleanupAndHelperCalls(decl.Body, name.Name)
+			}
+		}
+	}
+}
+
+/*
+ * eg: *testing.T
+ */
+func isTestingTPointer(expr ast.Expr) bool {
+	return isTestingPointer(expr, "T")
+}
+
+/*
+ * eg: *testing.M, as received by TestMain
+ */
+func isTestingMPointer(expr ast.Expr) bool {
+	return isTestingPointer(expr, "M")
+}
+
+/*
+ * eg: *testing.B, as received by benchmarks
+ */
+func isTestingBPointer(expr ast.Expr) bool {
+	return isTestingPointer(expr, "B")
+}
+
+func isTestingPointer(expr ast.Expr, name string) bool {
+	starExpr, ok := expr.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+
+	selectorExpr, ok := starExpr.X.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+
+	xIdent, ok := selectorExpr.X.(*ast.Ident)
+	return ok && xIdent.Name == "testing" && selectorExpr.Sel.Name == name
+}
@@ -0,0 +1,53 @@
+package converter
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+)
+
+/*
+ * CheckPackage checks a single package (and its children). See CheckPackages.
+ */
+func (c *Converter) CheckPackage(packageName string) (remaining []Diagnostic, diagnostics []Diagnostic, err error) {
+	return c.CheckPackages(packageName)
+}
+
+/*
+ * CheckPackages walks the same package trees that ConvertPackages would convert,
+ * and returns a diagnostic for every xunit style test that is still waiting
+ * to be converted. Ginkgo suite entry points (the TestXxx func that calls
+ * RunSpecs) are allowed, since every ginkgo suite needs one.
+ * Any problems found while walking the packages are returned separately.
+ */
+func (c *Converter) CheckPackages(patterns ...string) (remaining []Diagnostic, diagnostics []Diagnostic, err error) {
+	testfiles, _, diagnostics, err := c.findTestsForPatterns(patterns)
+	if err != nil {
+		return nil, diagnostics, err
+	}
+
+	for _, filename := range testfiles {
+		unconverted, err := findUnconvertedTestsInFile(filename)
+		if err != nil {
+			return nil, diagnostics, err
+		}
+		remaining = append(remaining, unconverted...)
+	}
+	return remaining, diagnostics, nil
+}
+
+func findUnconvertedTestsInFile(pathToFile string) (remaining []Diagnostic, err error) {
+	fileSet := token.NewFileSet()
+	rootNode, err := parser.ParseFile(fileSet, pathToFile, nil, 0)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing test file '%s':\n%s\n", pathToFile, err.Error())
+	}
+
+	for _, testFunc := range findTestFuncs(rootNode) {
+		remaining = append(remaining, Diagnostic{
+			Pos:     fileSet.Position(testFunc.Pos()),
+			Message: testFunc.Name.Name,
+		})
+	}
+	return remaining, nil
+}
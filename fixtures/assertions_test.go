@@ -0,0 +1,100 @@
+package tmp
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestComparisons(t *testing.T) {
+	got := Add(1, 2)
+	if got != 3 {
+		t.Errorf("expected 3, got %d", got)
+	}
+
+	if name := Name(); name != "calculator" {
+		t.Fatalf("unexpected name %s", name)
+	}
+
+	if got == 4 {
+		t.Error("should not be 4")
+	}
+
+	if got < 1 {
+		t.Error("too small:", got)
+	}
+
+	if 10 < got {
+		t.Fail()
+	}
+
+	if len(History()) != 1 {
+		t.FailNow()
+	}
+
+	result, ok := Lookup("answer")
+	if !ok {
+		t.Fatal("expected to find the answer")
+	}
+
+	if result == nil {
+		t.Fatal("expected a result")
+	}
+
+	if cached, _ := Lookup("answer"); cached != result {
+		t.Error("expected the cached result")
+	}
+
+	if !reflect.DeepEqual(result.Values, []int{42}) {
+		t.Errorf("unexpected values %v", result.Values)
+	}
+
+	if got != 3 && got != 4 {
+		t.Errorf("left alone, there is no single matcher for this")
+	}
+}
+
+func TestErrorChecks(t *testing.T) {
+	result, err := Lookup("answer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Save(result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, parseErr := Parse("nonsense")
+	if parseErr == nil {
+		t.Fatal("expected an error")
+	}
+
+	if parseErr.Error() != "cannot parse nonsense" {
+		t.Errorf("unexpected error %s", parseErr)
+	}
+
+	if !errors.Is(parseErr, ErrInvalid) {
+		t.Error("expected ErrInvalid")
+	}
+}
+
+type State string
+
+const wantCount = 2
+
+func TestTypedComparisons(t *testing.T) {
+	b := []byte("abc")
+	if b[0] != 'a' {
+		t.Errorf("unexpected first byte %c", b[0])
+	}
+
+	var st State = "running"
+	if st != "running" {
+		t.Fatal("not running")
+	}
+
+	var n int64 = 2
+	if n != wantCount {
+		t.Fatalf("expected %d, got %d", wantCount, n)
+	}
+}
@@ -0,0 +1,42 @@
+package tmp
+
+import (
+	"strings"
+	"testing"
+)
+
+func BenchmarkJoiningWords(b *testing.B) {
+	words := strings.Fields("the quick brown fox jumps over the lazy dog")
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if strings.Join(words, " ") == "" {
+			b.Fatal("expected the words to be joined")
+		}
+	}
+
+	b.ReportMetric(float64(len(words)), "words")
+}
+
+func BenchmarkSplittingLines(b *testing.B) {
+	for range b.N {
+		strings.Split("one\ntwo\nthree", "\n")
+	}
+}
+
+func BenchmarkWithTimers(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		input := strings.Repeat("a", i)
+		b.StartTimer()
+
+		strings.ToUpper(input)
+	}
+}
+
+func TestJoiningTwoWords(t *testing.T) {
+	if strings.Join([]string{"a", "b"}, " ") != "a b" {
+		t.Error("expected the words to be joined with spaces")
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright 2014 The Ginkgo Converter Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style license.
+
+package tmp
+
+import (
+	"strings"
+	"testing" // for the tests below
+)
+
+// TestUppercasing checks that words are shouted
+func TestUppercasing(t *testing.T) {
+	// the word is short on purpose
+	word := "hi"
+
+	shouted := strings.ToUpper(word) //nolint:staticcheck
+	if shouted != "HI" {
+		// this message explains what went wrong
+		t.Errorf("expected HI, got %s", shouted)
+	}
+}
+
+// shout is a helper that sits between two tests
+func shout(word string) string {
+	return strings.ToUpper(word) + "!" // exclamation for emphasis
+}
+
+/*
+ * TestShouting checks the helper
+ */
+func TestShouting(t *testing.T) {
+	// compare with the expected shout
+	if shout("hey") != "HEY!" {
+		t.Fail()
+	}
+	// shouting twice is left for later
+}
+
+// TestWhispering checks that words are whispered
+func TestWhispering(t *testing.T) {
+	word := "HI"
+
+	t.Run("lowercase", func(t *testing.T) {
+		if strings.ToLower(word) != "hi" {
+			t.Fail()
+		}
+	})
+}
+
+func TestMumbling(t *testing.T) {
+	t.Log(strings.Repeat("m", 3))
+	// nobody can hear this
+}
+
+// a comment at the end of the file
@@ -0,0 +1,44 @@
+package tmp
+
+import (
+	"fmt"
+	"sort"
+)
+
+func ExampleGreeting() {
+	fmt.Println("hello")
+	fmt.Println("world")
+	// Output:
+	// hello
+	// world
+}
+
+func ExampleSortingWords() {
+	words := []string{"b", "a"}
+	sort.Strings(words)
+	fmt.Println(words)
+	// Output: [a b]
+}
+
+func ExampleMap() {
+	for key, value := range map[string]int{"one": 1, "two": 2} {
+		fmt.Println(key, value)
+	}
+	// Unordered output:
+	// one 1
+	// two 2
+}
+
+func ExampleSilence() {
+	fmt.Print("")
+	// Output:
+}
+
+func Example_counting() {
+	fmt.Println(1, 2, 3)
+	// Output: 1 2 3
+}
+
+func ExampleCompileOnly() {
+	fmt.Println("not checked")
+}
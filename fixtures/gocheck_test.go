@@ -0,0 +1,45 @@
+package tmp
+
+import (
+	"os"
+	"testing"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) { TestingT(t) }
+
+type ConfigSuite struct {
+	dir    string
+	config *Config
+}
+
+var _ = Suite(&ConfigSuite{dir: "testdata"})
+
+func (s *ConfigSuite) SetUpTest(c *C) {
+	loaded, err := Load(s.dir)
+	c.Assert(err, IsNil)
+	s.config = loaded
+}
+
+func (s *ConfigSuite) TearDownTest(c *C) {
+	os.RemoveAll(s.config.CacheDir)
+}
+
+func (s *ConfigSuite) TestDefaults(c *C) {
+	c.Assert(s.config.Name, Equals, "example")
+	c.Check(s.config.Ports, DeepEquals, []int{80, 443})
+	c.Assert(s.config.Plugins, HasLen, 2, Commentf("plugins: %v", s.config.Plugins))
+	c.Assert(s.config.Parent, NotNil)
+	c.Assert(s.config.Name, Not(Equals), "")
+}
+
+func (s *ConfigSuite) TestMissingFile(c *C) {
+	_, err := Load(s.missing())
+	c.Assert(err, ErrorMatches, "open .*: no such file or directory")
+	c.Logf("checked %s", s.missing())
+}
+
+func (s *ConfigSuite) missing() string {
+	return s.dir + "/missing"
+}
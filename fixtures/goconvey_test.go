@@ -0,0 +1,54 @@
+package tmp
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestStack(t *testing.T) {
+	Convey("Given an empty stack", t, func() {
+		stack := &Stack{}
+
+		Reset(func() {
+			stack.Close()
+		})
+
+		So(stack.Len(), ShouldEqual, 0)
+
+		Convey("When an item is pushed", func() {
+			stack.Push("answer")
+
+			Convey("Then it has one item", func() {
+				So(stack.Len(), ShouldEqual, 1)
+				So(stack.Items(), ShouldResemble, []string{"answer"})
+			})
+
+			Convey("Then popping returns the item", func() {
+				item, err := stack.Pop()
+				So(err, ShouldBeNil)
+				So(item, ShouldStartWith, "ans")
+			})
+		})
+
+		Convey("Popping fails", func() {
+			_, err := stack.Pop()
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestParallelConvey(t *testing.T) {
+	Convey("Parsing a number", t, func(c C) {
+		value, err := Parse("4.2")
+		c.So(err, ShouldBeNil)
+		c.So(value, ShouldAlmostEqual, 4.2, 0.01)
+		c.So(value, ShouldBeGreaterThan, 4)
+	})
+}
+
+func TestUnsupportedAssertion(t *testing.T) {
+	Convey("Comparing times", t, func() {
+		So(Now(), ShouldHappenBefore, Later())
+	})
+}
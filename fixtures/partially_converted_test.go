@@ -0,0 +1,26 @@
+package tmp
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"testing"
+)
+
+func init() {
+	Describe("Testing with ginkgo", func() {
+		It("starts empty", func() {
+			Expect(NewCache().Keys()).To(BeEmpty())
+		})
+	})
+}
+
+// the cache forgets the keys it evicts
+func TestEviction(t *testing.T) {
+	cache := NewCache()
+	cache.Put("key", 1)
+
+	cache.Evict("key")
+	if len(cache.Keys()) != 0 {
+		t.Errorf("expected the key to be evicted")
+	}
+}
@@ -0,0 +1,60 @@
+package tmp
+
+import (
+	"testing"
+)
+
+func TestCalculator(t *testing.T) {
+	calculator := &Calculator{}
+	var total int
+
+	t.Run("adding", func(t *testing.T) {
+		total = calculator.Add(1, 2)
+		if total != 3 {
+			t.Errorf("expected 3, got %d", total)
+		}
+	})
+
+	t.Run("dividing", func(t *testing.T) {
+		t.Run("by a number", func(t *testing.T) {
+			if calculator.Divide(4, 2) != 2 {
+				t.Fail()
+			}
+		})
+
+		t.Run("by zero", func(st *testing.T) {
+			_, err := calculator.SafeDivide(4, 0)
+			if err == nil {
+				st.Fatal("expected an error")
+			}
+		})
+	})
+}
+
+func TestSubtestsWithUnknownSetup(t *testing.T) {
+	calculator := NewCalculator()
+
+	t.Run("adding", func(t *testing.T) {
+		calculator.Add(1, 2)
+	})
+}
+
+func TestStore(t *testing.T) {
+	const capacity = 2
+	type entry struct{ key string }
+
+	store := &Store{}
+	defer store.Close()
+
+	t.Run("saving", func(t *testing.T) {
+		if err := store.Save(entry{"a"}, capacity); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("loading", func(t *testing.T) {
+		if _, err := store.Load("a"); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package tmp
+
+import (
+	"fmt"
+	"testing"
+)
+
+type divisionCase struct {
+	a, b int
+	want int
+}
+
+func TestAdding(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b int
+		want int
+	}{
+		{name: "small numbers", a: 1, b: 2, want: 3},
+		{name: "zeroes"},
+		{"negative numbers", -1, -2, -3},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Add(tc.a, tc.b); got != tc.want {
+				t.Errorf("expected %d, got %d", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestDividing(t *testing.T) {
+	calculator := &Calculator{}
+
+	for name, tc := range map[string]divisionCase{
+		"evenly": {a: 4, b: 2, want: 2},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if calculator.Divide(tc.a, tc.b) != tc.want {
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestScaling(t *testing.T) {
+	tests := []struct {
+		name   string
+		factor float64 // what to scale 4 by
+		want   uint
+	}{
+		// scaling up
+		{name: "doubling", factor: 2, want: 8},
+		{name: "halving", factor: 1 / 2.0, want: 2},
+		{name: "nothing"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if Scale(4, tc.factor) != tc.want {
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestTableWithDynamicNames(t *testing.T) {
+	tests := []divisionCase{{1, 1, 1}}
+
+	for _, tc := range tests {
+		t.Run(fmt.Sprintf("%d/%d", tc.a, tc.b), func(t *testing.T) {
+			t.Log(tc)
+		})
+	}
+}
+
+func TestTableWithStatementsAfterTheLoop(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b int
+	}{
+		{"ones", 1, 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Log(Add(tc.a, tc.b))
+		})
+	}
+
+	t.Log("done")
+}
@@ -0,0 +1,37 @@
+package tmp
+
+import (
+	"flag"
+	"os"
+	"testing"
+)
+
+var addr string
+
+type Server struct {
+	addr string
+}
+
+func (s *Server) Stop() {}
+
+func TestMain(m *testing.M) {
+	flag.StringVar(&addr, "addr", "localhost:8080", "the address to serve the tests on")
+	flag.Parse()
+
+	workDir := "fixtures-workdir"
+	os.Mkdir(workDir, 0755)
+	defer os.RemoveAll(workDir)
+
+	server := &Server{addr: addr}
+
+	code := m.Run()
+
+	server.Stop()
+	os.Exit(code)
+}
+
+func TestServerAddr(t *testing.T) {
+	if addr == "" {
+		t.Fatal("expected an address to serve the tests on")
+	}
+}
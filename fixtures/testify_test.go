@@ -0,0 +1,29 @@
+package tmp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithTestify(t *testing.T) {
+	result, err := Lookup("answer")
+	require.NoError(t, err)
+	require.NotNil(t, result)
+
+	assert.Equal(t, 42, result.Value, "the answer is %d", 42)
+	assert.Len(t, result.Values, 1)
+	assert.Contains(t, result.Name, "answer")
+	assert.ElementsMatch(t, result.Values, []int{42})
+	assert.EqualError(t, Save(nil), "nothing to save")
+	assert.Greater(t, result.Value, 0)
+	assert.Panics(t, func() { Lookup("") })
+	assert.Eventually(t, result.Ready, time.Second, 10*time.Millisecond)
+	assert.FileExists(t, "answer.txt")
+
+	if assert.True(t, result.Cached) {
+		t.Log("cached")
+	}
+}
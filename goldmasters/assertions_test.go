@@ -0,0 +1,70 @@
+package tmp
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	mr "github.com/tjarratt/mr_t"
+)
+
+type State string
+
+const wantCount = 2
+
+func init() {
+	Describe("Testing with ginkgo", func() {
+		It("comparisons", func() {
+			got := Add(1, 2)
+			Expect(got).To(BeNumerically("==", 3), "expected 3, got %d", got)
+
+			name := Name()
+			Expect(name == "calculator").To(BeTrue(), "unexpected name %s", name)
+
+			Expect(got).NotTo(BeNumerically("==", 4), "should not be 4")
+
+			Expect(got).To(BeNumerically(">=", 1), "%v %v", "too small:", got)
+
+			Expect(got).To(BeNumerically("<=", 10))
+
+			Expect(History()).To(HaveLen(1))
+
+			result, ok := Lookup("answer")
+			Expect(ok).To(BeTrue(), "expected to find the answer")
+
+			Expect(result).NotTo(BeNil(), "expected a result")
+
+			cached, _ := Lookup("answer")
+			Expect(cached == result).To(BeTrue(), "expected the cached result")
+
+			Expect(result.Values).To(Equal([]int{42}), "unexpected values %v", result.Values)
+
+			if got != 3 && got != 4 {
+				mr.T().Errorf("left alone, there is no single matcher for this")
+			}
+		})
+
+		It("error checks", func() {
+			result, err := Lookup("answer")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(Save(result)).To(Succeed())
+
+			_, parseErr := Parse("nonsense")
+			Expect(parseErr).To(HaveOccurred(), "expected an error")
+
+			Expect(parseErr).To(MatchError("cannot parse nonsense"), "unexpected error %s", parseErr)
+
+			Expect(parseErr).To(MatchError(ErrInvalid), "expected ErrInvalid")
+		})
+
+		It("typed comparisons", func() {
+			b := []byte("abc")
+			Expect(b[0]).To(BeNumerically("==", 'a'), "unexpected first byte %c", b[0])
+
+			var st State = "running"
+			Expect(st == "running").To(BeTrue(), "not running")
+
+			var n int64 = 2
+			Expect(n == wantCount).To(BeTrue(), "expected %d, got %d", wantCount, n)
+		})
+	})
+}
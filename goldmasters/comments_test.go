@@ -0,0 +1,58 @@
+// Copyright 2014 The Ginkgo Converter Authors. All rights reserved.
+// Use of this source code is governed by an MIT-style license.
+
+package tmp
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	mr "github.com/tjarratt/mr_t"
+	"strings"
+)
+
+// shout is a helper that sits between two tests
+func shout(word string) string {
+	return strings.ToUpper(word) + "!" // exclamation for emphasis
+}
+
+func init() {
+	Describe("Testing with ginkgo", func() {
+		// TestUppercasing checks that words are shouted
+		It("uppercasing", func() {
+			// the word is short on purpose
+			word := "hi"
+
+			shouted := strings.ToUpper(word) //nolint:staticcheck
+			// this message explains what went wrong
+			Expect(shouted == "HI").To(BeTrue(), "expected HI, got %s", shouted)
+		})
+
+		/*
+		 * TestShouting checks the helper
+		 */
+		It("shouting", func() {
+			// compare with the expected shout
+			Expect(shout("hey") == "HEY!").To(BeTrue())
+			// shouting twice is left for later
+		})
+
+		// TestWhispering checks that words are whispered
+		Describe("whispering", func() {
+			var word string
+			BeforeEach(func() {
+				word = "HI"
+			})
+
+			It("lowercase", func() {
+				Expect(strings.ToLower(word) == "hi").To(BeTrue())
+			})
+		})
+
+		It("mumbling", func() {
+			mr.T().Log(strings.Repeat("m", 3))
+			// nobody can hear this
+		})
+	})
+}
+
+// a comment at the end of the file
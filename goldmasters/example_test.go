@@ -0,0 +1,72 @@
+package tmp
+
+import (
+	"fmt"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"os"
+	"sort"
+	"strings"
+)
+
+func ExampleCompileOnly() {
+	fmt.Println("not checked")
+}
+
+func init() {
+	Describe("Testing with ginkgo", func() {
+		outputOf := func(example func()) string {
+			file, err := os.CreateTemp("", "example")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.Remove(file.Name())
+			defer file.Close()
+			stdout := os.Stdout
+			os.Stdout = file
+			func() {
+				defer func() {
+					os.Stdout = stdout
+				}()
+				example()
+			}()
+			output, err := os.ReadFile(file.Name())
+			Expect(err).NotTo(HaveOccurred())
+			return strings.TrimSpace(string(output))
+		}
+
+		It("greeting", func() {
+			Expect(outputOf(func() {
+				fmt.Println("hello")
+				fmt.Println("world")
+			})).To(Equal(`hello
+world`))
+		})
+
+		It("sorting words", func() {
+			Expect(outputOf(func() {
+				words := []string{"b", "a"}
+				sort.Strings(words)
+				fmt.Println(words)
+			})).To(Equal("[a b]"))
+		})
+
+		It("map", func() {
+			Expect(strings.Split(outputOf(func() {
+				for key, value := range map[string]int{"one": 1, "two": 2} {
+					fmt.Println(key, value)
+				}
+			}), "\n")).To(ConsistOf("one 1", "two 2"))
+		})
+
+		It("silence", func() {
+			Expect(outputOf(func() {
+				fmt.Print("")
+			})).To(BeEmpty())
+		})
+
+		It("Example_counting", func() {
+			Expect(outputOf(func() {
+				fmt.Println(1, 2, 3)
+			})).To(Equal("1 2 3"))
+		})
+	})
+}
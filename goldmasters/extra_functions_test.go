@@ -8,6 +8,7 @@ import (
 func somethingImportant(t mr.TestingT, message *string) {
 	t.Log("Something important happened in a test: " + *message)
 }
+
 func init() {
 	Describe("Testing with ginkgo", func() {
 		It("something less important", func() {
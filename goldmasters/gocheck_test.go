@@ -0,0 +1,45 @@
+package tmp
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	mr "github.com/tjarratt/mr_t"
+	"os"
+)
+
+func init() {
+	Describe("Testing with ginkgo", func() {
+		Describe("config suite", func() {
+			var dir string = "testdata"
+			var config *Config
+
+			var missing func() string
+			missing = func() string {
+				return dir + "/missing"
+			}
+			BeforeEach(func() {
+				loaded, err := Load(dir)
+				Expect(err).NotTo(HaveOccurred())
+				config = loaded
+			})
+
+			AfterEach(func() {
+				os.RemoveAll(config.CacheDir)
+			})
+
+			It("defaults", func() {
+				Expect(config.Name).To(Equal("example"))
+				Expect(config.Ports).To(Equal([]int{80, 443}))
+				Expect(config.Plugins).To(HaveLen(2), "plugins: %v", config.Plugins)
+				Expect(config.Parent).NotTo(BeNil())
+				Expect(config.Name).NotTo(Equal(""))
+			})
+
+			It("missing file", func() {
+				_, err := Load(missing())
+				Expect(err).To(MatchError(MatchRegexp("^open .*: no such file or directory$")))
+				mr.T().Logf("checked %s", missing())
+			})
+		})
+	})
+}
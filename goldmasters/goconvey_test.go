@@ -0,0 +1,59 @@
+package tmp
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/smartystreets/goconvey/convey"
+	mr "github.com/tjarratt/mr_t"
+)
+
+func init() {
+	Describe("Testing with ginkgo", func() {
+		Describe("Given an empty stack", func() {
+			var stack *Stack
+			BeforeEach(func() {
+				stack = &Stack{}
+
+				Expect(stack.Len()).To(BeNumerically("==", 0))
+			})
+			AfterEach(func() {
+				stack.Close()
+			})
+
+			Context("When an item is pushed", func() {
+				BeforeEach(func() {
+					stack.Push("answer")
+				})
+
+				It("Then it has one item", func() {
+					Expect(stack.Len()).To(BeNumerically("==", 1))
+					Expect(stack.Items()).To(Equal([]string{"answer"}))
+				})
+
+				It("Then popping returns the item", func() {
+					item, err := stack.Pop()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(item).To(HavePrefix("ans"))
+				})
+			})
+
+			It("Popping fails", func() {
+				_, err := stack.Pop()
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		It("Parsing a number", func() {
+			value, err := Parse("4.2")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(value).To(BeNumerically("~", 4.2, 0.01))
+			Expect(value).To(BeNumerically(">", 4))
+		})
+
+		It("unsupported assertion", func() {
+			Convey("Comparing times", mr.T(), func() {
+				So(Now(), ShouldHappenBefore, Later())
+			})
+		})
+	})
+}
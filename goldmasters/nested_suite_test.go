@@ -1,4 +1,4 @@
-package nested_test
+package nested
 
 import (
 	. "github.com/onsi/ginkgo"
@@ -8,7 +8,6 @@ import (
 func init() {
 	Describe("Testing with ginkgo", func() {
 		It("something less important", func() {
-
 			whatever := &UselessStruct{}
 			mr.T().Fail(whatever.ImportantField != "SECRET_PASSWORD")
 		})
@@ -12,7 +12,6 @@ type UselessStruct struct {
 func init() {
 	Describe("Testing with ginkgo", func() {
 		It("something important", func() {
-
 			whatever := &UselessStruct{}
 			mr.T().Fail(whatever.ImportantField != "SECRET_PASSWORD")
 		})
@@ -0,0 +1,23 @@
+package tmp
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func init() {
+	Describe("Testing with ginkgo", func() {
+		It("starts empty", func() {
+			Expect(NewCache().Keys()).To(BeEmpty())
+		})
+
+		// the cache forgets the keys it evicts
+		It("eviction", func() {
+			cache := NewCache()
+			cache.Put("key", 1)
+
+			cache.Evict("key")
+			Expect(cache.Keys()).To(HaveLen(0), "expected the key to be evicted")
+		})
+	})
+}
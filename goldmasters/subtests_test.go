@@ -0,0 +1,66 @@
+package tmp
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	mr "github.com/tjarratt/mr_t"
+)
+
+func init() {
+	Describe("Testing with ginkgo", func() {
+		Describe("calculator", func() {
+			var calculator *Calculator
+			var total int
+			BeforeEach(func() {
+				calculator = &Calculator{}
+			})
+
+			It("adding", func() {
+				total = calculator.Add(1, 2)
+				Expect(total).To(BeNumerically("==", 3), "expected 3, got %d", total)
+			})
+
+			Context("dividing", func() {
+				It("by a number", func() {
+					Expect(calculator.Divide(4, 2)).To(BeNumerically("==", 2))
+				})
+
+				It("by zero", func() {
+					_, err := calculator.SafeDivide(4, 0)
+					Expect(err).To(HaveOccurred(), "expected an error")
+				})
+			})
+		})
+
+		It("subtests with unknown setup", func() {
+			calculator := NewCalculator()
+
+			mr.T().Run("adding", func(t mr.TestingT) {
+				calculator.Add(1, 2)
+			})
+		})
+
+		Describe("store", func() {
+			const capacity = 2
+			type entry struct{ key string }
+
+			var store *Store
+			BeforeEach(func() {
+				store = &Store{}
+			})
+			AfterEach(func() {
+				store.Close()
+			})
+
+			It("saving", func() {
+				err := store.Save(entry{"a"}, capacity)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("loading", func() {
+				_, err := store.Load("a")
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+	})
+}
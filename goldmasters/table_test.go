@@ -0,0 +1,69 @@
+package tmp
+
+import (
+	"fmt"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	mr "github.com/tjarratt/mr_t"
+)
+
+type divisionCase struct {
+	a, b int
+	want int
+}
+
+func init() {
+	Describe("Testing with ginkgo", func() {
+		DescribeTable("adding", func(name string, a int, b int, want int) {
+			got := Add(a, b)
+			Expect(got == want).To(BeTrue(), "expected %d, got %d", want, got)
+		}, Entry("small numbers", "small numbers", 1, 2, 3),
+			Entry("zeroes", "zeroes", 0, 0, 0),
+			Entry("negative numbers", "negative numbers", -1, -2, -3))
+
+		Describe("dividing", func() {
+			var calculator *Calculator
+			BeforeEach(func() {
+				calculator = &Calculator{}
+			})
+
+			DescribeTable("cases", func(name string, a int, b int, want int) {
+				Expect(calculator.Divide(a, b) == want).To(BeTrue())
+			}, Entry("evenly", "evenly", 4, 2, 2))
+		})
+
+		DescribeTable("scaling", func(name string, factor float64, want uint) {
+			Expect(Scale(4, factor) == want).To(BeTrue())
+		}, Entry("doubling", "doubling", float64(2), uint(8)),
+			Entry("halving", "halving", 1/2.0, uint(2)),
+			Entry("nothing", "nothing", float64(0), uint(0)))
+
+		It("table with dynamic names", func() {
+			tests := []divisionCase{{1, 1, 1}}
+
+			for _, tc := range tests {
+				mr.T().Run(fmt.Sprintf("%d/%d", tc.a, tc.b), func(t mr.TestingT) {
+					mr.T().Log(tc)
+				})
+			}
+		})
+
+		It("table with statements after the loop", func() {
+			tests := []struct {
+				name string
+				a, b int
+			}{
+				{"ones", 1, 1},
+			}
+
+			for _, tc := range tests {
+				mr.T().Run(tc.name, func(t mr.TestingT) {
+					mr.T().Log(Add(tc.a, tc.b))
+				})
+			}
+
+			mr.T().Log("done")
+		})
+	})
+}
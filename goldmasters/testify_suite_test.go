@@ -0,0 +1,82 @@
+package tmp
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/suite"
+)
+
+type DatabaseSuite struct {
+	suite.Suite
+	driver string
+	db     *Database
+}
+
+func (s *DatabaseSuite) SetupSuite() {
+	s.db = Open(s.driver)
+}
+
+func (s *DatabaseSuite) TearDownSuite() {
+	s.db.Close()
+}
+
+func (s *DatabaseSuite) SetupTest() {
+	s.Require().NoError(s.db.Truncate())
+}
+
+func (s *DatabaseSuite) TestInsert() {
+	id, err := s.db.Insert("answer", 42)
+	s.NoError(err)
+	s.Greater(id, 0)
+	s.Equal(42, s.lookup(id))
+}
+
+func (s *DatabaseSuite) TestMissingRows() {
+	require := s.Require()
+	_, err := s.db.Get(7)
+	require.EqualError(err, "not found")
+	s.T().Log("checked a missing row")
+}
+
+func (s *DatabaseSuite) lookup(id int) int {
+	value, err := s.db.Get(id)
+	s.Require().NoError(err)
+	return value
+}
+
+func TestDatabaseSuite(t *testing.T) {
+	suite.Run(t, &DatabaseSuite{driver: "sqlite"})
+}
+
+type CacheSuite struct {
+	suite.Suite
+}
+
+func (s *CacheSuite) TestSubtests() {
+	s.Run("empty", func() {
+		s.Empty(NewCache().Keys())
+	})
+}
+
+func TestCacheSuite(t *testing.T) {
+	suite.Run(t, new(CacheSuite))
+}
+
+func init() {
+	Describe("Testing with ginkgo", func() {
+		Describe("stack suite", func() {
+			var items []int
+
+			BeforeEach(func() {
+				items = []int{1, 2}
+			})
+
+			It("push", func() {
+				items = append(items, 3)
+				Expect(items).To(HaveLen(3))
+			})
+		})
+	})
+}
@@ -0,0 +1,34 @@
+package tmp
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/assert"
+	mr "github.com/tjarratt/mr_t"
+)
+
+func init() {
+	Describe("Testing with ginkgo", func() {
+		It("with testify", func() {
+			result, err := Lookup("answer")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).NotTo(BeNil())
+
+			Expect(result.Value).To(Equal(42), "the answer is %d", 42)
+			Expect(result.Values).To(HaveLen(1))
+			Expect(result.Name).To(ContainSubstring("answer"))
+			Expect(result.Values).To(ConsistOf([]int{42}))
+			Expect(Save(nil)).To(MatchError("nothing to save"))
+			Expect(result.Value).To(BeNumerically(">", 0))
+			Expect(func() { Lookup("") }).To(Panic())
+			Eventually(result.Ready, time.Second, 10*time.Millisecond).Should(BeTrue())
+			assert.FileExists(mr.T(), "answer.txt")
+
+			if assert.True(mr.T(), result.Cached) {
+				mr.T().Log("cached")
+			}
+		})
+	})
+}
@@ -0,0 +1,45 @@
+package tmp
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gmeasure"
+	"strings"
+	"testing"
+	"time"
+)
+
+func BenchmarkWithTimers(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		input := strings.Repeat("a", i)
+		b.StartTimer()
+
+		strings.ToUpper(input)
+	}
+}
+
+var _ = Describe("Testing with ginkgo", func() {
+	It("joining words", Label("benchmark"), func() {
+		experiment := gmeasure.NewExperiment("joining words")
+		AddReportEntry(experiment.Name, experiment)
+		words := strings.Fields("the quick brown fox jumps over the lazy dog")
+		experiment.SampleDuration("joining words", func(_ int) {
+			Expect(strings.Join(words, " ") == "").To(BeFalse(), "expected the words to be joined")
+		}, gmeasure.SamplingConfig{N: 100, Duration: time.Second})
+
+		experiment.RecordValue("words", float64(len(words)))
+	})
+
+	It("splitting lines", Label("benchmark"), func() {
+		experiment := gmeasure.NewExperiment("splitting lines")
+		AddReportEntry(experiment.Name, experiment)
+		experiment.SampleDuration("splitting lines", func(_ int) {
+			strings.Split("one\ntwo\nthree", "\n")
+		}, gmeasure.SamplingConfig{N: 100, Duration: time.Second})
+	})
+
+	It("joining two words", func() {
+		Expect(strings.Join([]string{"a", "b"}, " ") == "a b").To(BeTrue(), "expected the words to be joined with spaces")
+	})
+})
@@ -0,0 +1,15 @@
+package tmp
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+)
+
+func somethingImportant(t GinkgoTInterface, message *string) {
+	t.Log("Something important happened in a test: " + *message)
+}
+
+var _ = Describe("Testing with ginkgo", func() {
+	It("something less important", func() {
+		somethingImportant(GinkgoT(), &"hello!")
+	})
+})
@@ -0,0 +1,40 @@
+package tmp
+
+import (
+	"flag"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"os"
+)
+
+var addr string
+
+type Server struct {
+	addr string
+}
+
+func (s *Server) Stop() {}
+
+func init() {
+	flag.StringVar(&addr, "addr", "localhost:8080", "the address to serve the tests on")
+}
+
+var server *Server
+
+var _ = BeforeSuite(func() {
+	workDir := "fixtures-workdir"
+	os.Mkdir(workDir, 0755)
+	DeferCleanup(os.RemoveAll, workDir)
+
+	server = &Server{addr: addr}
+})
+
+var _ = AfterSuite(func() {
+	server.Stop()
+})
+
+var _ = Describe("Testing with ginkgo", func() {
+	It("server addr", func() {
+		Expect(addr == "").To(BeFalse(), "expected an address to serve the tests on")
+	})
+})
@@ -0,0 +1,74 @@
+package tmp
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/stretchr/testify/suite"
+)
+
+type CacheSuite struct {
+	suite.Suite
+}
+
+func (s *CacheSuite) TestSubtests() {
+	s.Run("empty", func() {
+		s.Empty(NewCache().Keys())
+	})
+}
+
+func TestCacheSuite(t *testing.T) {
+	suite.Run(t, new(CacheSuite))
+}
+
+var _ = Describe("Testing with ginkgo", func() {
+	Describe("database suite", Ordered, func() {
+		var driver string = "sqlite"
+		var db *Database
+
+		var lookup func(id int) int
+		lookup = func(id int) int {
+			value, err := db.Get(id)
+			Expect(err).NotTo(HaveOccurred())
+			return value
+		}
+		BeforeAll(func() {
+			db = Open(driver)
+		})
+
+		AfterAll(func() {
+			db.Close()
+		})
+
+		BeforeEach(func() {
+			Expect(db.Truncate()).NotTo(HaveOccurred())
+		})
+
+		It("insert", func() {
+			id, err := db.Insert("answer", 42)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(id).To(BeNumerically(">", 0))
+			Expect(lookup(id)).To(Equal(42))
+		})
+
+		It("missing rows", func() {
+			_, err := db.Get(7)
+			Expect(err).To(MatchError("not found"))
+			GinkgoT().Log("checked a missing row")
+		})
+	})
+
+	Describe("stack suite", func() {
+		var items []int
+
+		BeforeEach(func() {
+			items = []int{1, 2}
+		})
+
+		It("push", func() {
+			items = append(items, 3)
+			Expect(items).To(HaveLen(3))
+		})
+	})
+})
@@ -0,0 +1,13 @@
+package tmp_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestTmp(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Tmp Suite")
+}
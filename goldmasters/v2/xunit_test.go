@@ -0,0 +1,29 @@
+package tmp
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+)
+
+type UselessStruct struct {
+	ImportantField string
+	T              GinkgoTInterface
+}
+
+var testFunc = func(t GinkgoTInterface, arg *string) {}
+
+var _ = Describe("Testing with ginkgo", func() {
+	It("something important", func() {
+		whatever := &UselessStruct{
+			T:              GinkgoT(),
+			ImportantField: "twisty maze of passages",
+		}
+		app := "string value"
+		something := &UselessStruct{ImportantField: app}
+
+		GinkgoT().Fail(whatever.ImportantField != "SECRET_PASSWORD")
+		assert.Equal(GinkgoT(), whatever.ImportantField, "SECRET_PASSWORD")
+		var foo = func(t GinkgoTInterface) {}
+		foo()
+		testFunc(GinkgoT(), "something")
+	})
+})
@@ -1,30 +1,160 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/tjarratt/ginkgo-converter/converter"
 )
 
 func main() {
-	if len(os.Args) != 2 {
-		println(fmt.Sprintf("usage: %s /path/to/your/package", os.Args[0]))
+	dryRun := flag.Bool("dry-run", false, "print a unified diff of every change instead of writing files")
+	patchFile := flag.String("patch", "", "with --dry-run, also write the diff to this file (usable with `git apply`)")
+	check := flag.Bool("check", false, "list remaining xunit style tests and exit non-zero if there are any")
+	noRecursive := flag.Bool("no-recursive", false, "only convert the named packages, not the packages below them")
+	configFile := flag.String("config", "", "a JSON config file naming the suite and spec templates to use")
+	suiteTemplate := flag.String("suite-template", "", "a text/template file used to create new suite files")
+	specTemplate := flag.String("spec-template", "", "a text/template file for the container each converted file's specs are added to")
+	ginkgoVersion := flag.String("ginkgo-version", "v1", "the version of ginkgo to convert to: v1 or v2")
+	testify := flag.Bool("testify", false, "convert testify's assert and require calls into gomega assertions")
+	goConvey := flag.Bool("goconvey", false, "convert GoConvey's Convey blocks and So assertions into ginkgo containers and gomega assertions")
+	examples := flag.Bool("examples", false, "convert examples into specs that check what they print")
+	benchmarks := flag.Bool("benchmarks", false, "with --ginkgo-version v2, convert benchmarks into specs that measure them with gmeasure")
+	testingT := flag.String("testing-t", "", "what *testing.T is replaced with: mr_t, ginkgo or the import path of your own package (default mr_t for v1, ginkgo for v2)")
+	testingTFunc := flag.String("testing-t-func", "T", "with --testing-t path/to/package, the func in that package that returns a T")
+	testingTType := flag.String("testing-t-type", "TestingT", "with --testing-t path/to/package, the type in that package that helper funcs receive instead of a *testing.T")
+	flag.Usage = func() {
+		println(fmt.Sprintf("usage: %s [--dry-run [--patch file.patch] | --check] [--no-recursive] [--ginkgo-version v2] /path/to/your/package [./other/packages/...]", os.Args[0]))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 || (*patchFile != "" && !*dryRun) || (*check && *dryRun) {
+		flag.Usage()
 		os.Exit(1)
 	}
 
-	defer func() {
-		err := recover()
-		if err != nil {
-			switch err := err.(type) {
-			case error:
-				println(err.Error())
-			case string:
-				println(err)
-			default:
-				println(fmt.Sprintf("unexpected error: %#v", err))
-			}
+	cfg := config{}
+	if *configFile != "" {
+		var err error
+		cfg, err = readConfig(*configFile)
+		exitOnError(err)
+	}
+	if *suiteTemplate != "" {
+		cfg.SuiteTemplate = *suiteTemplate
+	}
+	if *specTemplate != "" {
+		cfg.SpecTemplate = *specTemplate
+	}
+
+	options := converter.Options{NonRecursive: *noRecursive, GinkgoVersion: *ginkgoVersion, Testify: *testify, GoConvey: *goConvey, Benchmarks: *benchmarks, Examples: *examples}
+	options.TestingT = testingTBackend(*testingT, *testingTFunc, *testingTType)
+
+	var err error
+	options.SuiteTemplate, err = readTemplate(cfg.SuiteTemplate)
+	exitOnError(err)
+	options.SpecTemplate, err = readTemplate(cfg.SpecTemplate)
+	exitOnError(err)
+
+	c := converter.New(options)
+
+	if *check {
+		remaining, diagnostics, err := c.CheckPackages(flag.Args()...)
+		printDiagnostics(diagnostics)
+		exitOnError(err)
+
+		for _, test := range remaining {
+			fmt.Println(test)
+		}
+
+		if len(remaining) > 0 {
+			println(fmt.Sprintf("found %d test(s) that have not been converted to ginkgo", len(remaining)))
 			os.Exit(1)
 		}
-	}()
+		return
+	}
+
+	changes, diagnostics, err := c.ConvertPackages(flag.Args()...)
+	printDiagnostics(diagnostics)
+	exitOnError(err)
+
+	if !*dryRun {
+		for _, change := range changes {
+			exitOnError(change.Apply())
+		}
+		return
+	}
+
+	patch := diffForChanges(changes)
+	fmt.Print(patch)
+
+	if *patchFile != "" {
+		err := ioutil.WriteFile(*patchFile, []byte(patch), 0644)
+		if err != nil {
+			exitOnError(fmt.Errorf("Error writing patch file '%s':\n%s\n", *patchFile, err.Error()))
+		}
+	}
+}
+
+/*
+ * Translates the --testing-t flags into a backend, or nil to use the
+ * default for the ginkgo version. Anything other than mr_t or ginkgo is
+ * the import path of a package providing the func and type named by
+ * --testing-t-func and --testing-t-type.
+ */
+func testingTBackend(name, funcName, typeName string) *converter.TestingTBackend {
+	switch name {
+	case "":
+		return nil
+	case "mr_t":
+		return &converter.MrTBackend
+	case "ginkgo":
+		return &converter.GinkgoTBackend
+	}
 
-	RewritePackage(os.Args[1])
+	return &converter.TestingTBackend{
+		PackageName: path.Base(name),
+		ImportPath:  name,
+		TFunc:       funcName,
+		TType:       typeName,
+	}
+}
+
+func exitOnError(err error) {
+	if err != nil {
+		println(err.Error())
+		os.Exit(1)
+	}
+}
+
+/*
+ * Diagnostics go to stderr so they never end up in a --dry-run patch
+ */
+func printDiagnostics(diagnostics []converter.Diagnostic) {
+	for _, diagnostic := range diagnostics {
+		println(diagnostic.String())
+	}
+}
+
+/*
+ * Concatenates the diffs for every change. Paths are made relative to the
+ * working directory so the patch can be applied from there.
+ */
+func diffForChanges(changes []converter.FileChange) (patch string) {
+	cwd, err := os.Getwd()
+	exitOnError(err)
+
+	for _, change := range changes {
+		path, err := filepath.Rel(cwd, change.Path)
+		if err != nil {
+			path = change.Path
+		}
+
+		patch += unifiedDiff(filepath.ToSlash(path), change.Original, change.Converted)
+	}
+	return
 }
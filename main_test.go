@@ -33,6 +33,119 @@ func init() {
 			})
 		})
 
+		It("rewrites t.Run subtests as nested Describes, Contexts and Its", func() {
+			withTempDir(func(dir string) {
+				runGinkgoConvert()
+
+				convertedFile := readConvertedFileNamed(dir, "subtests_test.go")
+				goldMaster := readGoldMasterNamed("subtests_test.go")
+				Expect(convertedFile).To(Equal(goldMaster))
+			})
+		})
+
+		It("rewrites table driven tests as DescribeTables", func() {
+			withTempDir(func(dir string) {
+				runGinkgoConvert()
+
+				convertedFile := readConvertedFileNamed(dir, "table_test.go")
+				goldMaster := readGoldMasterNamed("table_test.go")
+				Expect(convertedFile).To(Equal(goldMaster))
+			})
+		})
+
+		It("rewrites guards that fail the test as gomega assertions", func() {
+			withTempDir(func(dir string) {
+				runGinkgoConvert()
+
+				convertedFile := readConvertedFileNamed(dir, "assertions_test.go")
+				goldMaster := readGoldMasterNamed("assertions_test.go")
+				Expect(convertedFile).To(Equal(goldMaster))
+			})
+		})
+
+		It("rewrites testify assertions as gomega assertions with --testify", func() {
+			withTempDir(func(dir string) {
+				runGinkgoConvert("--testify")
+
+				convertedFile := readConvertedFileNamed(dir, "testify_test.go")
+				goldMaster := readGoldMasterNamed("testify_test.go")
+				Expect(convertedFile).To(Equal(goldMaster))
+			})
+		})
+
+		It("rewrites testify suites as Describe blocks", func() {
+			withTempDir(func(dir string) {
+				runGinkgoConvert()
+
+				convertedFile := readConvertedFileNamed(dir, "testify_suite_test.go")
+				goldMaster := readGoldMasterNamed("testify_suite_test.go")
+				Expect(convertedFile).To(Equal(goldMaster))
+			})
+		})
+
+		It("rewrites gocheck suites as Describe blocks", func() {
+			withTempDir(func(dir string) {
+				runGinkgoConvert()
+
+				convertedFile := readConvertedFileNamed(dir, "gocheck_test.go")
+				goldMaster := readGoldMasterNamed("gocheck_test.go")
+				Expect(convertedFile).To(Equal(goldMaster))
+			})
+		})
+
+		It("rewrites GoConvey's Convey blocks as containers and specs with --goconvey", func() {
+			withTempDir(func(dir string) {
+				runGinkgoConvert("--goconvey")
+
+				convertedFile := readConvertedFileNamed(dir, "goconvey_test.go")
+				goldMaster := readGoldMasterNamed("goconvey_test.go")
+				Expect(convertedFile).To(Equal(goldMaster))
+			})
+		})
+
+		It("rewrites TestMain as BeforeSuite and AfterSuite", func() {
+			withTempDir(func(dir string) {
+				runGinkgoConvert()
+
+				convertedFile := readConvertedFileNamed(dir, "test_main_test.go")
+				goldMaster := readGoldMasterNamed("test_main_test.go")
+				Expect(convertedFile).To(Equal(goldMaster))
+			})
+		})
+
+		It("keeps comments next to the code they annotate", func() {
+			withTempDir(func(dir string) {
+				runGinkgoConvert()
+
+				convertedFile := readConvertedFileNamed(dir, "comments_test.go")
+				goldMaster := readGoldMasterNamed("comments_test.go")
+				Expect(convertedFile).To(Equal(goldMaster))
+			})
+		})
+
+		It("adds new tests to the Describe of a file converted before", func() {
+			withTempDir(func(dir string) {
+				runGinkgoConvert()
+
+				convertedFile := readConvertedFileNamed(dir, "partially_converted_test.go")
+				goldMaster := readGoldMasterNamed("partially_converted_test.go")
+				Expect(convertedFile).To(Equal(goldMaster))
+			})
+		})
+
+		It("leaves files with no tests left to convert untouched", func() {
+			withTempDir(func(dir string) {
+				runGinkgoConvert()
+				suiteFile := readConvertedFileNamed(dir, "tmp_suite_test.go")
+				runGinkgoConvert()
+
+				Expect(readConvertedFileNamed(dir, "xunit_test.go")).To(Equal(readGoldMasterNamed("xunit_test.go")))
+				Expect(readConvertedFileNamed(dir, "partially_converted_test.go")).To(Equal(readGoldMasterNamed("partially_converted_test.go")))
+				Expect(readConvertedFileNamed(dir, "tmp_suite_test.go")).To(Equal(suiteFile))
+				Expect(readConvertedFileNamed(dir, "example_test.go")).To(Equal(readFixtureNamed("example_test.go")))
+			})
+		})
+
 		It("rewrites tests in the package dir that belong to other packages", func() {
 			withTempDir(func(tempDir string) {
 				runGinkgoConvert()
@@ -53,6 +166,115 @@ func init() {
 			})
 		})
 
+		It("converts to ginkgo v2 with --ginkgo-version v2", func() {
+			withTempDir(func(dir string) {
+				runGinkgoConvert("--ginkgo-version", "v2")
+
+				for _, name := range []string{"xunit_test.go", "extra_functions_test.go", "testify_suite_test.go", "test_main_test.go", "tmp_suite_test.go"} {
+					convertedFile := readConvertedFileNamed(dir, name)
+					goldMaster := readGoldMasterNamed(filepath.Join("v2", name))
+					Expect(convertedFile).To(Equal(goldMaster))
+				}
+			})
+		})
+
+		It("rewrites examples as specs checking their output with --examples", func() {
+			withTempDir(func(dir string) {
+				runGinkgoConvert("--examples")
+
+				convertedFile := readConvertedFileNamed(dir, "example_test.go")
+				goldMaster := readGoldMasterNamed("example_test.go")
+				Expect(convertedFile).To(Equal(goldMaster))
+			})
+		})
+
+		It("converts benchmarks into gmeasure experiments with --benchmarks", func() {
+			withTempDir(func(dir string) {
+				runGinkgoConvert("--ginkgo-version", "v2", "--benchmarks")
+
+				convertedFile := readConvertedFileNamed(dir, "benchmark_test.go")
+				goldMaster := readGoldMasterNamed(filepath.Join("v2", "benchmark_test.go"))
+				Expect(convertedFile).To(Equal(goldMaster))
+			})
+		})
+
+		It("replaces *testing.T with GinkgoT() instead of mr_t with --testing-t ginkgo", func() {
+			withTempDir(func(dir string) {
+				runGinkgoConvert("--testing-t", "ginkgo")
+
+				convertedFile := readConvertedFileNamed(dir, "xunit_test.go")
+				Expect(convertedFile).To(ContainSubstring(`. "github.com/onsi/ginkgo"`))
+				Expect(convertedFile).To(ContainSubstring(`T              GinkgoTInterface`))
+				Expect(convertedFile).To(ContainSubstring(`testFunc(GinkgoT(), "something")`))
+				Expect(convertedFile).NotTo(ContainSubstring("mr_t"))
+			})
+		})
+
+		It("accepts relative paths to packages", func() {
+			withTempDir(func(dir string) {
+				runGinkgoConvertWithArgs("./tmp/nested")
+
+				convertedFile := readConvertedFileNamed(dir, "nested", "nested_test.go")
+				goldMaster := readGoldMasterNamed("nested_test.go")
+				Expect(convertedFile).To(Equal(goldMaster))
+
+				untouchedFile := readConvertedFileNamed(dir, "xunit_test.go")
+				Expect(untouchedFile).To(Equal(readFixtureNamed("xunit_test.go")))
+			})
+		})
+
+		It("accepts several packages and ./... patterns", func() {
+			withTempDir(func(dir string) {
+				runGinkgoConvertWithArgs("--no-recursive", "./tmp/nested", "github.com/tjarratt/ginkgo-converter/tmp")
+
+				Expect(readConvertedFileNamed(dir, "nested", "nested_test.go")).To(Equal(readGoldMasterNamed("nested_test.go")))
+				Expect(readConvertedFileNamed(dir, "xunit_test.go")).To(Equal(readGoldMasterNamed("xunit_test.go")))
+			})
+		})
+
+		It("converts every package matched by ./...", func() {
+			withTempDir(func(dir string) {
+				runGinkgoConvertWithArgs("./tmp/...")
+
+				Expect(readConvertedFileNamed(dir, "nested", "nested_test.go")).To(Equal(readGoldMasterNamed("nested_test.go")))
+				Expect(readConvertedFileNamed(dir, "xunit_test.go")).To(Equal(readGoldMasterNamed("xunit_test.go")))
+			})
+		})
+
+		It("leaves child packages alone with --no-recursive", func() {
+			withTempDir(func(dir string) {
+				runGinkgoConvert("--no-recursive")
+
+				Expect(readConvertedFileNamed(dir, "xunit_test.go")).To(Equal(readGoldMasterNamed("xunit_test.go")))
+				Expect(readConvertedFileNamed(dir, "nested", "nested_test.go")).To(Equal(readFixtureNamed(filepath.Join("nested", "nested_test.go"))))
+
+				_, err := os.Stat(filepath.Join(dir, "nested", "nested_suite_test.go"))
+				Expect(os.IsNotExist(err)).To(BeTrue())
+			})
+		})
+
+		It("skips testdata, vendor, hidden and non-package directories without failing", func() {
+			withTempDir(func(dir string) {
+				for _, skipped := range []string{"testdata", "vendor", "_old", ".git"} {
+					err := os.MkdirAll(filepath.Join(dir, skipped), os.ModePerm)
+					Expect(err).NotTo(HaveOccurred())
+					err = ioutil.WriteFile(filepath.Join(dir, skipped, "broken_test.go"), []byte("this is not go"), 0600)
+					Expect(err).NotTo(HaveOccurred())
+				}
+
+				err := os.MkdirAll(filepath.Join(dir, "docs", "nested"), os.ModePerm)
+				Expect(err).NotTo(HaveOccurred())
+				err = os.Symlink(dir, filepath.Join(dir, "nested", "loop"))
+				Expect(err).NotTo(HaveOccurred())
+
+				runGinkgoConvert()
+
+				Expect(readConvertedFileNamed(dir, "xunit_test.go")).To(Equal(readGoldMasterNamed("xunit_test.go")))
+				Expect(readConvertedFileNamed(dir, "nested", "nested_test.go")).To(Equal(readGoldMasterNamed("nested_test.go")))
+				Expect(readConvertedFileNamed(dir, "testdata", "broken_test.go")).To(Equal("this is not go"))
+			})
+		})
+
 		Context("ginkgo test suite files", func() {
 			It("creates a ginkgo test suite file for the package you specified", func() {
 				withTempDir(func(dir string) {
@@ -74,6 +296,47 @@ func init() {
 				})
 			})
 
+			It("renders custom suite and spec templates named in a config file", func() {
+				withTempDir(func(dir string) {
+					templatesDir, err := ioutil.TempDir("", "ginkgo-converter-templates")
+					Expect(err).NotTo(HaveOccurred())
+					defer os.RemoveAll(templatesDir)
+
+					suiteTemplate := `package {{.PackageName}}
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"example.com/testsupport"
+)
+
+func Test{{.FormattedName}}(t *testing.T) {
+	testsupport.Init()
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "{{.SuiteTitle}} ({{.ImportPath}})")
+}
+`
+					specTemplate := `Describe("{{.PackageName}}", func() {})`
+					config := `{"suiteTemplate": "suite.tmpl", "specTemplate": "spec.tmpl"}`
+
+					Expect(ioutil.WriteFile(filepath.Join(templatesDir, "suite.tmpl"), []byte(suiteTemplate), 0600)).To(Succeed())
+					Expect(ioutil.WriteFile(filepath.Join(templatesDir, "spec.tmpl"), []byte(specTemplate), 0600)).To(Succeed())
+					Expect(ioutil.WriteFile(filepath.Join(templatesDir, "config.json"), []byte(config), 0600)).To(Succeed())
+
+					runGinkgoConvert("--config", filepath.Join(templatesDir, "config.json"))
+
+					testsuite := readConvertedFileNamed(dir, "nested", "nested_suite_test.go")
+					Expect(testsuite).To(ContainSubstring("\ttestsupport.Init()\n"))
+					Expect(testsuite).To(ContainSubstring(`RunSpecs(t, "Nested Suite (github.com/tjarratt/ginkgo-converter/tmp/nested)")`))
+
+					convertedFile := readConvertedFileNamed(dir, "outside_package_test.go")
+					Expect(convertedFile).To(ContainSubstring(`Describe("tmp_test", func() {`))
+				})
+			})
+
 			It("gracefully handles existing test suite files", func() {
 				withTempDir(func(dir string) {
 					cwd, err := os.Getwd()
@@ -86,6 +349,57 @@ func init() {
 				})
 			})
 		})
+
+		Context("with --dry-run", func() {
+			It("prints a diff instead of rewriting files", func() {
+				withTempDir(func(dir string) {
+					output := runGinkgoConvert("--dry-run")
+
+					Expect(output).To(ContainSubstring("+++ b/tmp/xunit_test.go"))
+					Expect(output).To(ContainSubstring("+\t\tIt(\"something important\", func() {"))
+					Expect(output).To(ContainSubstring("--- /dev/null\n+++ b/tmp/nested/nested_suite_test.go"))
+
+					convertedFile := readConvertedFileNamed(dir, "xunit_test.go")
+					fixture := readFixtureNamed("xunit_test.go")
+					Expect(convertedFile).To(Equal(fixture))
+
+					_, err := os.Stat(filepath.Join(dir, "tmp_suite_test.go"))
+					Expect(os.IsNotExist(err)).To(BeTrue())
+				})
+			})
+
+			It("writes the same diff to a patch file", func() {
+				withTempDir(func(dir string) {
+					patchFile := filepath.Join(dir, "conversion.patch")
+					output := runGinkgoConvert("--dry-run", "--patch", patchFile)
+
+					patch := readConvertedFileNamed(patchFile)
+					Expect(patch).To(Equal(output))
+				})
+			})
+		})
+
+		Context("with --check", func() {
+			It("lists the remaining xunit tests and fails", func() {
+				withTempDir(func(dir string) {
+					output := runFailingGinkgoConvert("--check")
+
+					Expect(output).To(ContainSubstring(filepath.Join(dir, "xunit_test.go") + ":14: TestSomethingImportant\n"))
+					Expect(output).To(ContainSubstring(filepath.Join(dir, "nested", "nested_test.go") + ":7: TestSomethingLessImportant\n"))
+				})
+			})
+
+			It("allows the ginkgo suite entry points once a package is converted", func() {
+				withTempDir(func(dir string) {
+					runGinkgoConvert()
+
+					// only the tests running suites that could not be converted are left
+					output := runFailingGinkgoConvert("--check")
+					suites := filepath.Join(dir, "testify_suite_test.go")
+					Expect(output).To(Equal(suites + ":49: TestDatabaseSuite\n" + suites + ":63: TestCacheSuite\n"))
+				})
+			})
+		})
 	})
 }
 
@@ -128,18 +442,36 @@ func copyFixturesIntoTempDir(relativePathToFixtures, tempDir string) {
 	}
 }
 
-func runGinkgoConvert() {
+func runGinkgoConvert(flags ...string) string {
+	return runGinkgoConvertWithArgs(append(flags, "github.com/tjarratt/ginkgo-converter/tmp")...)
+}
+
+func runGinkgoConvertWithArgs(args ...string) string {
 	cwd, err := os.Getwd()
 	Expect(err).NotTo(HaveOccurred())
 
 	pathToExecutable := filepath.Join(cwd, "bin", "ginkgo-convert")
-	cmd := exec.Command(pathToExecutable, "github.com/tjarratt/ginkgo-convert/tmp")
+	cmd := exec.Command(pathToExecutable, args...)
 	out, err := cmd.Output()
 
 	if err != nil {
 		println("ginkgo-convert failed:", string(out))
 	}
 	Expect(err).NotTo(HaveOccurred())
+
+	return string(out)
+}
+
+func runFailingGinkgoConvert(flags ...string) string {
+	cwd, err := os.Getwd()
+	Expect(err).NotTo(HaveOccurred())
+
+	pathToExecutable := filepath.Join(cwd, "bin", "ginkgo-convert")
+	args := append(flags, "github.com/tjarratt/ginkgo-converter/tmp")
+	out, err := exec.Command(pathToExecutable, args...).Output()
+	Expect(err).To(HaveOccurred())
+
+	return string(out)
 }
 
 func readGoldMasterNamed(filename string) string {
@@ -152,6 +484,16 @@ func readGoldMasterNamed(filename string) string {
 	return string(bytes)
 }
 
+func readFixtureNamed(filename string) string {
+	cwd, err := os.Getwd()
+	Expect(err).NotTo(HaveOccurred())
+
+	bytes, err := ioutil.ReadFile(filepath.Join(cwd, "fixtures", filename))
+	Expect(err).NotTo(HaveOccurred())
+
+	return string(bytes)
+}
+
 func readConvertedFileNamed(pathComponents ...string) string {
 	pathToFile := filepath.Join(pathComponents...)
 	bytes, err := ioutil.ReadFile(pathToFile)
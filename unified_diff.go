@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const diffContextLines = 3
+
+/*
+ * A diffLine is a single line of a diff, prefixed with ' ', '-' or '+'
+ */
+type diffLine struct {
+	kind byte
+	text string
+}
+
+/*
+ * Creates a git-style unified diff between the original and converted
+ * contents of a file. The output can be read by humans and by `git apply`.
+ * If original is nil, the file is treated as a newly created file.
+ */
+func unifiedDiff(path string, original, converted []byte) string {
+	lines := diffLines(splitLines(original), splitLines(converted))
+	if !containsEdits(lines) {
+		return ""
+	}
+
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "diff --git a/%s b/%s\n", path, path)
+	if original == nil {
+		buffer.WriteString("new file mode 100644\n")
+		buffer.WriteString("--- /dev/null\n")
+	} else {
+		fmt.Fprintf(&buffer, "--- a/%s\n", path)
+	}
+	fmt.Fprintf(&buffer, "+++ b/%s\n", path)
+
+	for _, hunk := range hunksForLines(lines) {
+		writeHunk(&buffer, lines, hunk)
+	}
+
+	return buffer.String()
+}
+
+/*
+ * Splits file contents into lines, keeping the trailing newline of each line
+ * so that a missing newline at the end of the file can be reported.
+ */
+func splitLines(contents []byte) []string {
+	if len(contents) == 0 {
+		return nil
+	}
+
+	lines := strings.SplitAfter(string(contents), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+/*
+ * Computes the shortest edit script between two slices of lines using
+ * Myers' algorithm, returning every line tagged as kept, removed or added.
+ * The trace keeps the diagonals each step could have reached, k = -d-1..d+1
+ * for step d, rather than a copy of the whole of v, so that it grows with
+ * the square of the number of edits rather than with the length of the files.
+ */
+func diffLines(a, b []string) []diffLine {
+	n, m := len(a), len(b)
+	max := n + m
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	trace := [][]int{}
+
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int{}, v[offset-d-1:offset+d+2]...))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrackDiff(a, b, trace, d)
+			}
+		}
+	}
+
+	return nil
+}
+
+/*
+ * Walks the Myers trace backwards from the end of both inputs to build
+ * the list of diff lines in order.
+ */
+func backtrackDiff(a, b []string, trace [][]int, d int) []diffLine {
+	lines := []diffLine{}
+	x, y := len(a), len(b)
+
+	for ; d >= 0; d-- {
+		// trace[d] starts at diagonal -d-1
+		v, offset := trace[d], d+1
+		k := x - y
+
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			lines = append(lines, diffLine{' ', a[x-1]})
+			x, y = x-1, y-1
+		}
+
+		if d > 0 {
+			if x == prevX {
+				lines = append(lines, diffLine{'+', b[y-1]})
+			} else {
+				lines = append(lines, diffLine{'-', a[x-1]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+		lines[i], lines[j] = lines[j], lines[i]
+	}
+	return lines
+}
+
+func containsEdits(lines []diffLine) bool {
+	for _, line := range lines {
+		if line.kind != ' ' {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ * Groups diff lines into hunks: ranges of edits surrounded by up to
+ * diffContextLines of unchanged lines. Edits that are close together
+ * share a single hunk.
+ */
+func hunksForLines(lines []diffLine) (hunks [][2]int) {
+	for i := 0; i < len(lines); i++ {
+		if lines[i].kind == ' ' {
+			continue
+		}
+
+		start := i - diffContextLines
+		if start < 0 {
+			start = 0
+		}
+
+		end := i
+		for j := i; j < len(lines) && j <= end+2*diffContextLines; j++ {
+			if lines[j].kind != ' ' {
+				end = j
+			}
+		}
+
+		i = end
+		end += diffContextLines + 1
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		if len(hunks) > 0 && hunks[len(hunks)-1][1] >= start {
+			hunks[len(hunks)-1][1] = end
+		} else {
+			hunks = append(hunks, [2]int{start, end})
+		}
+	}
+	return
+}
+
+func writeHunk(buffer *bytes.Buffer, lines []diffLine, hunk [2]int) {
+	oldStart, newStart := 1, 1
+	for _, line := range lines[:hunk[0]] {
+		if line.kind != '+' {
+			oldStart++
+		}
+		if line.kind != '-' {
+			newStart++
+		}
+	}
+
+	oldCount, newCount := 0, 0
+	for _, line := range lines[hunk[0]:hunk[1]] {
+		if line.kind != '+' {
+			oldCount++
+		}
+		if line.kind != '-' {
+			newCount++
+		}
+	}
+
+	if oldCount == 0 {
+		oldStart--
+	}
+	if newCount == 0 {
+		newStart--
+	}
+
+	fmt.Fprintf(buffer, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+	for _, line := range lines[hunk[0]:hunk[1]] {
+		buffer.WriteByte(line.kind)
+		buffer.WriteString(line.text)
+		if !strings.HasSuffix(line.text, "\n") {
+			buffer.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}